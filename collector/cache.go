@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Cache serves the metrics of its collectors from snapshots, so several
+// Prometheus replicas scraping the exporter hit JMX once per ttl. A snapshot
+// older than ttl is refreshed by a single collection in its own goroutine
+// and served as is until the refresh completes. Only the scrapes arriving
+// before the first snapshot wait for, and share, its collection.
+type Cache struct {
+	ttl     time.Duration
+	entries []*cacheEntry
+
+	age *prometheus.Desc
+}
+
+type cacheEntry struct {
+	name      string
+	collector prometheus.Collector
+
+	mu        sync.Mutex
+	metrics   []prometheus.Metric
+	collected time.Time
+	refresh   chan struct{}
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl: ttl,
+
+		age: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "exporter", "cache_age_seconds"),
+			"The age of the cached metrics served for the collector in seconds.",
+			[]string{"collector"}, nil,
+		),
+	}
+}
+
+// Add caches the metrics of collector, exported as the collector label of
+// hbase_exporter_cache_age_seconds. Add must not be called once the cache
+// is registered.
+func (c *Cache) Add(name string, collector prometheus.Collector) {
+	c.entries = append(c.entries, &cacheEntry{
+		name:      name,
+		collector: collector,
+	})
+}
+
+func (c *Cache) Describe(ch chan<- *prometheus.Desc) {
+	for _, entry := range c.entries {
+		entry.collector.Describe(ch)
+	}
+
+	ch <- c.age
+}
+
+func (c *Cache) Collect(ch chan<- prometheus.Metric) {
+	var wg sync.WaitGroup
+	wg.Add(len(c.entries))
+	for _, entry := range c.entries {
+		go func(entry *cacheEntry) {
+			defer wg.Done()
+
+			metrics, collected := entry.snapshot(c.ttl)
+			for _, metric := range metrics {
+				ch <- metric
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.age,
+				prometheus.GaugeValue,
+				time.Since(collected).Seconds(),
+				entry.name,
+			)
+		}(entry)
+	}
+	wg.Wait()
+}
+
+// snapshot returns the cached metrics, starting a refresh when they are
+// older than ttl. It only waits for the refresh when there is no snapshot
+// yet.
+func (e *cacheEntry) snapshot(ttl time.Duration) ([]prometheus.Metric, time.Time) {
+	e.mu.Lock()
+	if !e.collected.IsZero() && time.Since(e.collected) < ttl {
+		defer e.mu.Unlock()
+		return e.metrics, e.collected
+	}
+	if e.refresh == nil {
+		e.refresh = make(chan struct{})
+		go e.collect(e.refresh)
+	}
+	if !e.collected.IsZero() {
+		defer e.mu.Unlock()
+		return e.metrics, e.collected
+	}
+	refresh := e.refresh
+	e.mu.Unlock()
+
+	<-refresh
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.metrics, e.collected
+}
+
+func (e *cacheEntry) collect(done chan struct{}) {
+	metrics := collectMetrics(e.collector)
+
+	e.mu.Lock()
+	e.metrics = metrics
+	e.collected = time.Now()
+	e.refresh = nil
+	e.mu.Unlock()
+
+	close(done)
+}
+
+// collectMetrics returns the metrics collected by collector in one slice.
+func collectMetrics(collector prometheus.Collector) []prometheus.Metric {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+
+	var metrics []prometheus.Metric
+	for metric := range ch {
+		metrics = append(metrics, metric)
+	}
+
+	return metrics
+}
@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// countCollector exports the number of its collections. Every collection
+// but the first waits for release.
+type countCollector struct {
+	desc    *prometheus.Desc
+	count   int32
+	release chan struct{}
+}
+
+func (c *countCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *countCollector) Collect(ch chan<- prometheus.Metric) {
+	count := atomic.AddInt32(&c.count, 1)
+	if count > 1 {
+		<-c.release
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count))
+}
+
+func TestCacheServesStaleSnapshot(t *testing.T) {
+	c := &countCollector{
+		desc:    prometheus.NewDesc(prometheus.BuildFQName(namespace, "test", "collections"), "A test gauge.", nil, nil),
+		release: make(chan struct{}),
+	}
+	cache := NewCache(10 * time.Millisecond)
+	cache.Add("test", c)
+
+	if got := gatherValues(t, cache)["hbase_test_collections{}"]; got != 1 {
+		t.Fatalf("got %v collections, want 1", got)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// The expired snapshot is served while its refresh waits for release,
+	// and the refresh is shared by the scrapes arriving meanwhile.
+	for i := 0; i < 2; i++ {
+		done := make(chan map[string]float64)
+		go func() {
+			done <- gatherValues(t, cache)
+		}()
+		select {
+		case got := <-done:
+			if got["hbase_test_collections{}"] != 1 {
+				t.Errorf("got %v collections, want the stale snapshot of 1", got["hbase_test_collections{}"])
+			}
+		case <-time.After(time.Second):
+			t.Fatal("the scrape waited for the refresh")
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if count := atomic.LoadInt32(&c.count); count != 2 {
+		t.Errorf("got %d collections, want a single shared refresh", count)
+	}
+
+	close(c.release)
+	deadline := time.Now().Add(time.Second)
+	for gatherValues(t, cache)["hbase_test_collections{}"] != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("the refreshed snapshot was never served")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
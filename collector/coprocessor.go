@@ -0,0 +1,141 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var defaultHBaseCoprocessorLabels = []string{"host", "role", "coprocessor_type", "class", "metric"}
+
+// HBaseCoprocessor collects the execution time histograms of the coprocessors
+// loaded by a master or regionserver. Every coprocessor publishes its own
+// bean, Coprocessor.<type>.CP_<class>, so the beans are discovered on every
+// scrape.
+type HBaseCoprocessor struct {
+	logger  log.Logger
+	jmx     *JmxClient
+	service string
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	time *prometheus.Desc
+}
+
+// NewHBaseCoprocessor returns a collector for the coprocessor beans of
+// service, which is either MasterService or RegionServerService.
+func NewHBaseCoprocessor(logger log.Logger, jmx *JmxClient, service string) *HBaseCoprocessor {
+	subsystem := "coprocessor"
+
+	return &HBaseCoprocessor{
+		logger:  logger,
+		jmx:     jmx,
+		service: service,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase coprocessor endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase coprocessor scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		time: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "execution_time_ms"),
+			"The execution time of coprocessor calls in milliseconds, by coprocessor and timer.",
+			defaultHBaseCoprocessorLabels, nil,
+		),
+	}
+}
+
+func (m *HBaseCoprocessor) upGauge() prometheus.Gauge {
+	return m.up
+}
+
+func (m *HBaseCoprocessor) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.time
+
+	ch <- m.up.Desc()
+	ch <- m.totalScrapes.Desc()
+	ch <- m.jsonParseFailures.Desc()
+}
+
+func (m *HBaseCoprocessor) Collect(ch chan<- prometheus.Metric) {
+	m.totalScrapes.Inc()
+	defer func() {
+		ch <- m.up
+		ch <- m.totalScrapes
+		ch <- m.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name=Coprocessor.*")
+	if err != nil {
+		if isJmxReadError(err) {
+			m.jsonParseFailures.Inc()
+		}
+		m.up.Set(0)
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to fetch coprocessor metrics",
+			"err", err,
+		)
+		return
+	}
+
+	// A server without coprocessors has no coprocessor beans, which is
+	// not a failed scrape.
+	beans, err := allBeans(bts)
+	if err != nil && err != errNoBeans {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode coprocessor metrics",
+			"err", err,
+		)
+		return
+	}
+	m.up.Set(1)
+
+	role := strings.ToLower(m.service)
+
+	for _, bean := range beans {
+		// e.g. Coprocessor.Region.CP_org.apache.phoenix.coprocessor.ScanRegionObserver
+		name := strings.TrimPrefix(beanProperty(bean.Get("name").String(), "name"), "Coprocessor.")
+		i := strings.Index(name, ".")
+		if i < 0 {
+			continue
+		}
+		coprocessorType := strings.ToLower(name[:i])
+		class := strings.TrimPrefix(name[i+1:], "CP_")
+
+		attrs := bean.Map()
+		host := attrs["tag.Hostname"].String()
+
+		for attr := range attrs {
+			if !strings.HasSuffix(attr, "_num_ops") {
+				continue
+			}
+
+			timer := strings.TrimSuffix(attr, "_num_ops")
+			count, sum, quantiles, ok := histogramSummary(attrs, timer)
+			if !ok {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstSummary(
+				m.time,
+				count,
+				sum,
+				quantiles,
+				host, role, coprocessorType, class, snakeCase(timer),
+			)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestHBaseCoprocessor(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		service string
+		fixture string
+		want    map[string]float64
+		absent  []string
+		timers  int
+	}{
+		{
+			name:    "regionserver",
+			service: RegionServerService,
+			fixture: "coprocessors_regionserver.json",
+			want: map[string]float64{
+				`hbase_coprocessor_up{}`: 1,
+				`hbase_coprocessor_execution_time_ms_count{class="org.apache.phoenix.coprocessor.ScanRegionObserver",coprocessor_type="region",host="rs1.example.com",metric="pre_scanner_open",role="regionserver"}`:                    10,
+				`hbase_coprocessor_execution_time_ms_sum{class="org.apache.phoenix.coprocessor.ScanRegionObserver",coprocessor_type="region",host="rs1.example.com",metric="pre_scanner_open",role="regionserver"}`:                      30,
+				`hbase_coprocessor_execution_time_ms_count{class="org.apache.phoenix.coprocessor.ScanRegionObserver",coprocessor_type="region",host="rs1.example.com",metric="pre_get_op",role="regionserver"}`:                          4,
+				`hbase_coprocessor_execution_time_ms_count{class="org.apache.hadoop.hbase.security.access.AccessController",coprocessor_type="regionserver",host="rs1.example.com",metric="pre_stop_region_server",role="regionserver"}`: 1,
+				`hbase_coprocessor_execution_time_ms_count{class="org.example.AuditWALObserver",coprocessor_type="wal",host="rs1.example.com",metric="pre_wal_write",role="regionserver"}`:                                               6,
+			},
+			// The bean name without a type and class is skipped.
+			timers: 4,
+		},
+		{
+			name:    "master",
+			service: MasterService,
+			fixture: "coprocessors_master.json",
+			want: map[string]float64{
+				`hbase_coprocessor_up{}`: 1,
+				`hbase_coprocessor_execution_time_ms_count{class="org.apache.hadoop.hbase.security.access.AccessController",coprocessor_type="master",host="master1.example.com",metric="pre_create_table",role="master"}`: 2,
+				`hbase_coprocessor_execution_time_ms_sum{class="org.apache.hadoop.hbase.security.access.AccessController",coprocessor_type="master",host="master1.example.com",metric="pre_create_table",role="master"}`:   100,
+			},
+			timers: 1,
+		},
+		{
+			// A server without coprocessors has no beans, which is not a
+			// failed scrape.
+			name:    "no coprocessors",
+			service: RegionServerService,
+			want: map[string]float64{
+				`hbase_coprocessor_up{}`: 1,
+			},
+			absent: []string{"hbase_coprocessor_execution_time_ms"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fixtures := map[string]string{}
+			if test.fixture != "" {
+				fixtures["Hadoop:service=HBase,name=Coprocessor.*"] = test.fixture
+			}
+			jmx := newJmxFixtureClient(t, fixtures)
+
+			got := gatherValues(t, NewHBaseCoprocessor(log.NewNopLogger(), jmx, test.service))
+			checkValues(t, got, test.want, test.absent...)
+
+			timers := 0
+			for key := range got {
+				if strings.HasPrefix(key, "hbase_coprocessor_execution_time_ms_count") {
+					timers++
+				}
+			}
+			if timers != test.timers {
+				t.Errorf("got %d timers, want %d", timers, test.timers)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tidwall/gjson"
+)
+
+// hbaseHistogramQuantiles maps the percentile suffixes of an HBase metrics2
+// histogram to prometheus summary quantiles.
+var hbaseHistogramQuantiles = map[string]float64{
+	"25th_percentile":   0.25,
+	"median":            0.5,
+	"75th_percentile":   0.75,
+	"90th_percentile":   0.9,
+	"95th_percentile":   0.95,
+	"98th_percentile":   0.98,
+	"99th_percentile":   0.99,
+	"99.9th_percentile": 0.999,
+}
+
+type hbaseHistogram struct {
+	Attr string
+	Desc *prometheus.Desc
+}
+
+// histogramSummary converts the attributes of the histogram attr, e.g.
+// QueueCallTime_num_ops, QueueCallTime_mean, QueueCallTime_99th_percentile,
+// into the count, sum and quantiles of a summary. The sum is approximated
+// from the mean because HBase does not publish it.
+func histogramSummary(bean map[string]gjson.Result, attr string) (uint64, float64, map[float64]float64, bool) {
+	numOps, ok := bean[attr+"_num_ops"]
+	if !ok {
+		return 0, 0, nil, false
+	}
+
+	count := numOps.Uint()
+	sum := bean[attr+"_mean"].Float() * float64(count)
+
+	quantiles := make(map[float64]float64, len(hbaseHistogramQuantiles))
+	for suffix, q := range hbaseHistogramQuantiles {
+		if v, ok := bean[attr+"_"+suffix]; ok {
+			quantiles[q] = v.Float()
+		}
+	}
+
+	return count, sum, quantiles, true
+}
+
+// collectHistograms emits a summary for each histogram present in bean.
+func collectHistograms(ch chan<- prometheus.Metric, histograms []*hbaseHistogram, bean map[string]gjson.Result, labels ...string) {
+	for _, histogram := range histograms {
+		count, sum, quantiles, ok := histogramSummary(bean, histogram.Attr)
+		if !ok {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstSummary(
+			histogram.Desc,
+			count,
+			sum,
+			quantiles,
+			labels...,
+		)
+	}
+}
@@ -0,0 +1,281 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseIpcLabels      = []string{"host", "role"}
+	defaultHBaseIpcLabelValues = func(hbaseIpc hbaseIpcResponse) []string {
+		return []string{
+			hbaseIpc.Host,
+			strings.ToLower(hbaseIpc.Role),
+		}
+	}
+)
+
+type hbaseIpcMetric struct {
+	Type   prometheus.ValueType
+	Desc   *prometheus.Desc
+	Value  func(hbaseIpc hbaseIpcResponse) float64
+	Labels func(hbaseIpc hbaseIpcResponse) []string
+}
+
+// HBaseIpc collects the rpc server metrics of a master or regionserver.
+type HBaseIpc struct {
+	logger  log.Logger
+	jmx     *JmxClient
+	service string
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	metrics    []*hbaseIpcMetric
+	histograms []*hbaseHistogram
+	exceptions *prometheus.Desc
+}
+
+// NewHBaseIpc returns a collector for the IPC bean of service, which is
+// either MasterService or RegionServerService.
+func NewHBaseIpc(logger log.Logger, jmx *JmxClient, service string) *HBaseIpc {
+	subsystem := "ipc"
+
+	return &HBaseIpc{
+		logger:  logger,
+		jmx:     jmx,
+		service: service,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase IPC endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase IPC scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		metrics: []*hbaseIpcMetric{
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "queue_size"),
+					"The number of bytes in the call queues.",
+					defaultHBaseIpcLabels, nil,
+				),
+				Value: func(hbaseIpc hbaseIpcResponse) float64 {
+					return hbaseIpc.QueueSize
+				},
+				Labels: defaultHBaseIpcLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "num_calls_in_general_queue"),
+					"The number of calls in the general call queue.",
+					defaultHBaseIpcLabels, nil,
+				),
+				Value: func(hbaseIpc hbaseIpcResponse) float64 {
+					return hbaseIpc.NumCallsInGeneralQueue
+				},
+				Labels: defaultHBaseIpcLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "num_calls_in_replication_queue"),
+					"The number of calls in the replication call queue.",
+					defaultHBaseIpcLabels, nil,
+				),
+				Value: func(hbaseIpc hbaseIpcResponse) float64 {
+					return hbaseIpc.NumCallsInReplicationQueue
+				},
+				Labels: defaultHBaseIpcLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "num_calls_in_priority_queue"),
+					"The number of calls in the priority call queue.",
+					defaultHBaseIpcLabels, nil,
+				),
+				Value: func(hbaseIpc hbaseIpcResponse) float64 {
+					return hbaseIpc.NumCallsInPriorityQueue
+				},
+				Labels: defaultHBaseIpcLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "num_active_handler"),
+					"The number of active rpc handlers.",
+					defaultHBaseIpcLabels, nil,
+				),
+				Value: func(hbaseIpc hbaseIpcResponse) float64 {
+					return hbaseIpc.NumActiveHandler
+				},
+				Labels: defaultHBaseIpcLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "num_open_connections"),
+					"The number of open rpc connections.",
+					defaultHBaseIpcLabels, nil,
+				),
+				Value: func(hbaseIpc hbaseIpcResponse) float64 {
+					return hbaseIpc.NumOpenConnections
+				},
+				Labels: defaultHBaseIpcLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sent_bytes"),
+					"The number of bytes sent by the rpc server.",
+					defaultHBaseIpcLabels, nil,
+				),
+				Value: func(hbaseIpc hbaseIpcResponse) float64 {
+					return hbaseIpc.SentBytes
+				},
+				Labels: defaultHBaseIpcLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "received_bytes"),
+					"The number of bytes received by the rpc server.",
+					defaultHBaseIpcLabels, nil,
+				),
+				Value: func(hbaseIpc hbaseIpcResponse) float64 {
+					return hbaseIpc.ReceivedBytes
+				},
+				Labels: defaultHBaseIpcLabelValues,
+			},
+		},
+
+		histograms: []*hbaseHistogram{
+			{
+				Attr: "QueueCallTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "queue_call_time_ms"),
+					"The time calls spent in the call queue in milliseconds.",
+					defaultHBaseIpcLabels, nil,
+				),
+			},
+			{
+				Attr: "ProcessCallTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "process_call_time_ms"),
+					"The time spent processing calls in milliseconds.",
+					defaultHBaseIpcLabels, nil,
+				),
+			},
+		},
+
+		exceptions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "exceptions"),
+			"The number of exceptions thrown by the rpc server, by exception.",
+			append(defaultHBaseIpcLabels, "exception"), nil,
+		),
+	}
+}
+
+func (m *HBaseIpc) upGauge() prometheus.Gauge {
+	return m.up
+}
+
+func (m *HBaseIpc) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range m.metrics {
+		ch <- metric.Desc
+	}
+	for _, histogram := range m.histograms {
+		ch <- histogram.Desc
+	}
+	ch <- m.exceptions
+
+	ch <- m.up.Desc()
+	ch <- m.totalScrapes.Desc()
+	ch <- m.jsonParseFailures.Desc()
+}
+
+func (m *HBaseIpc) Collect(ch chan<- prometheus.Metric) {
+	m.totalScrapes.Inc()
+	defer func() {
+		ch <- m.up
+		ch <- m.totalScrapes
+		ch <- m.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name="+m.service+",sub=IPC")
+	if err != nil {
+		if isJmxReadError(err) {
+			m.jsonParseFailures.Inc()
+		}
+		m.up.Set(0)
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to fetch ipc metrics",
+			"err", err,
+		)
+		return
+	}
+
+	bean, err := firstBean(bts)
+	if err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode ipc metrics",
+			"err", err,
+		)
+		return
+	}
+
+	var hbaseIpcResp hbaseIpcResponse
+	if err := json.Unmarshal([]byte(bean.Raw), &hbaseIpcResp); err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode ipc metrics",
+			"err", err,
+		)
+		return
+	}
+	m.up.Set(1)
+
+	for _, metric := range m.metrics {
+		ch <- prometheus.MustNewConstMetric(
+			metric.Desc,
+			metric.Type,
+			metric.Value(hbaseIpcResp),
+			metric.Labels(hbaseIpcResp)...,
+		)
+	}
+
+	labels := defaultHBaseIpcLabelValues(hbaseIpcResp)
+	attrs := bean.Map()
+
+	collectHistograms(ch, m.histograms, attrs, labels...)
+
+	// "exceptions" alone is the sum of all the exceptions.* attributes.
+	for k, v := range attrs {
+		if !strings.HasPrefix(k, "exceptions.") {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			m.exceptions,
+			prometheus.CounterValue,
+			v.Float(),
+			append(labels, strings.TrimPrefix(k, "exceptions."))...,
+		)
+	}
+}
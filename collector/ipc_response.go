@@ -0,0 +1,14 @@
+package collector
+
+type hbaseIpcResponse struct {
+	Host                       string  `json:"tag.Hostname"`
+	Role                       string  `json:"tag.Context"`
+	QueueSize                  float64 `json:"queueSize"`
+	NumCallsInGeneralQueue     float64 `json:"numCallsInGeneralQueue"`
+	NumCallsInReplicationQueue float64 `json:"numCallsInReplicationQueue"`
+	NumCallsInPriorityQueue    float64 `json:"numCallsInPriorityQueue"`
+	NumActiveHandler           float64 `json:"numActiveHandler"`
+	NumOpenConnections         float64 `json:"numOpenConnections"`
+	SentBytes                  float64 `json:"sentBytes"`
+	ReceivedBytes              float64 `json:"receivedBytes"`
+}
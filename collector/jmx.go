@@ -0,0 +1,337 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// MasterService is the jmx bean name used by HBase master processes.
+	MasterService = "Master"
+	// RegionServerService is the jmx bean name used by HBase regionserver processes.
+	RegionServerService = "RegionServer"
+)
+
+// JmxClient queries the jmx servlet of one HBase process.
+type JmxClient struct {
+	url             url.URL
+	client          *http.Client
+	maxResponseSize int64
+}
+
+// NewJmxClient returns a client for the jmx servlet behind u. A response
+// larger than maxResponseSize bytes fails, so a regionserver with thousands
+// of regions cannot exhaust the memory of the exporter, and a request taking
+// longer than timeout is cancelled. Either limit is disabled if 0.
+func NewJmxClient(u *url.URL, maxResponseSize int64, timeout time.Duration) *JmxClient {
+	return &JmxClient{
+		url:             *u,
+		client:          &http.Client{Timeout: timeout},
+		maxResponseSize: maxResponseSize,
+	}
+}
+
+// host returns the host name of the configured url.
+func (c *JmxClient) host() string {
+	return c.url.Hostname()
+}
+
+// errJmxResponseTooLarge is returned when reading a jmx response beyond the
+// maximum size of its client.
+var errJmxResponseTooLarge = errors.New("jmx response exceeds the maximum size")
+
+// jmxBody reads a jmx response body up to limit bytes, or without limit if
+// limit is 0.
+type jmxBody struct {
+	io.ReadCloser
+	limit int64
+	read  int64
+}
+
+func (b *jmxBody) Read(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return b.ReadCloser.Read(p)
+	}
+
+	left := b.limit - b.read
+	if left <= 0 {
+		// The body is too large only if there is anything left to read.
+		var probe [1]byte
+		n, err := b.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, errJmxResponseTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > left {
+		p = p[:left]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
+
+// openJmx queries the jmx servlet of c with qry and returns the body, which
+// the caller must close.
+func openJmx(c *JmxClient, qry string) (io.ReadCloser, error) {
+	u := c.url
+	url := u.String() + "?" + "qry=" + qry
+	res, err := c.client.Get(url)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jmx from %s://%s:%s%s: %s",
+			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("HTTP Request failed with code %d", res.StatusCode)
+	}
+
+	return &jmxBody{ReadCloser: res.Body, limit: c.maxResponseSize}, nil
+}
+
+// closeJmx closes a body returned by openJmx.
+func closeJmx(logger log.Logger, body io.Closer) {
+	if err := body.Close(); err != nil {
+		_ = level.Warn(logger).Log(
+			"msg", "failed to close http.Client",
+			"err", err,
+		)
+	}
+}
+
+// jmxReadError is returned by fetchJmx when the body of a response fails to
+// read, e.g. with errJmxResponseTooLarge. Collectors count it as a JSON parse
+// failure, as they did before fetchJmx read the body for them.
+type jmxReadError struct {
+	err error
+}
+
+func (e *jmxReadError) Error() string {
+	return fmt.Sprintf("failed to read jmx response: %s", e.err)
+}
+
+func (e *jmxReadError) Unwrap() error {
+	return e.err
+}
+
+// isJmxReadError reports whether err is a jmxReadError.
+func isJmxReadError(err error) bool {
+	var readErr *jmxReadError
+	return errors.As(err, &readErr)
+}
+
+// fetchJmx queries the jmx servlet of c with qry and returns the raw body.
+func fetchJmx(logger log.Logger, c *JmxClient, qry string) ([]byte, error) {
+	body, err := openJmx(c, qry)
+	if err != nil {
+		return nil, err
+	}
+	defer closeJmx(logger, body)
+
+	bts, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, &jmxReadError{err: err}
+	}
+
+	return bts, nil
+}
+
+// streamFirstBean queries the jmx servlet of c with qry and calls fn with
+// each scalar attribute of the first bean as it is decoded, so a response of
+// tens of megabytes is never held in memory at once. The rest of the response
+// is not read.
+func streamFirstBean(logger log.Logger, c *JmxClient, qry string, fn func(attr string, v gjson.Result)) error {
+	body, err := openJmx(c, qry)
+	if err != nil {
+		return err
+	}
+	defer closeJmx(logger, body)
+
+	dec := json.NewDecoder(body)
+	dec.UseNumber()
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key != "beans" {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		if !dec.More() {
+			return errNoBeans
+		}
+		if err := expectDelim(dec, '{'); err != nil {
+			return err
+		}
+
+		for dec.More() {
+			attr, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			name, ok := attr.(string)
+			if !ok {
+				return fmt.Errorf("invalid jmx response")
+			}
+
+			v, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if delim, ok := v.(json.Delim); ok {
+				// Only scalar attributes are metrics.
+				if err := skipValue(dec, delim); err != nil {
+					return err
+				}
+				continue
+			}
+			fn(name, tokenResult(v))
+		}
+
+		return expectDelim(dec, '}')
+	}
+
+	return errNoBeans
+}
+
+// tokenResult converts a scalar token of a json.Decoder using numbers into
+// the gjson.Result the collectors expect.
+func tokenResult(token json.Token) gjson.Result {
+	switch v := token.(type) {
+	case json.Number:
+		num, _ := v.Float64()
+		return gjson.Result{Type: gjson.Number, Raw: string(v), Num: num}
+	case string:
+		return gjson.Result{Type: gjson.String, Str: v}
+	case bool:
+		if v {
+			return gjson.Result{Type: gjson.True}
+		}
+		return gjson.Result{Type: gjson.False}
+	default:
+		return gjson.Result{Type: gjson.Null}
+	}
+}
+
+// skipValue reads the tokens of the object or array opened by delim.
+func skipValue(dec *json.Decoder, delim json.Delim) error {
+	depth := 1
+	for depth > 0 {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// expectDelim reads the next token of dec and fails unless it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("invalid jmx response")
+	}
+
+	return nil
+}
+
+// errNoBeans is returned by allBeans and firstBean when the query of a jmx
+// response matched no bean.
+var errNoBeans = errors.New("no beans in jmx response")
+
+// allBeans returns every bean of a jmx response.
+func allBeans(bts []byte) ([]gjson.Result, error) {
+	if !gjson.ValidBytes(bts) {
+		return nil, fmt.Errorf("invalid jmx response")
+	}
+
+	beans := gjson.GetBytes(bts, "beans").Array()
+	if len(beans) == 0 {
+		return nil, errNoBeans
+	}
+
+	return beans, nil
+}
+
+// firstBean returns the first bean of a jmx response.
+func firstBean(bts []byte) (gjson.Result, error) {
+	beans, err := allBeans(bts)
+	if err != nil {
+		return gjson.Result{}, err
+	}
+
+	return beans[0], nil
+}
+
+// beanProperty returns the value of key in a bean name such as
+// Hadoop:service=HBase,name=RegionServer,sub=WAL.
+func beanProperty(name, key string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	for _, kv := range strings.Split(name, ",") {
+		if strings.HasPrefix(kv, key+"=") {
+			return strings.TrimPrefix(kv, key+"=")
+		}
+	}
+
+	return ""
+}
+
+// snakeCase turns a CamelCase metric prefix such as ServerCrash or
+// preWALWrite into server_crash or pre_wal_write.
+func snakeCase(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, c := range runes {
+		if unicode.IsUpper(c) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,406 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tidwall/gjson"
+)
+
+const regionsQry = "Hadoop:service=HBase,name=RegionServer,sub=Regions"
+
+// newJmxTestServer serves body as the response to every jmx query.
+func newJmxTestServer(tb testing.TB, body []byte) *url.URL {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	tb.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL + "/jmx")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return u
+}
+
+// newJmxFixtureClient returns a jmx client for a servlet answering each
+// query with the response in the testdata file it maps to, and with no
+// beans otherwise.
+func newJmxFixtureClient(tb testing.TB, fixtures map[string]string) *JmxClient {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, ok := fixtures[r.URL.Query().Get("qry")]
+		if !ok {
+			_, _ = w.Write([]byte(`{"beans":[]}`))
+			return
+		}
+
+		bts, err := ioutil.ReadFile("testdata/" + file)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(bts)
+	}))
+	tb.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL + "/jmx")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return NewJmxClient(u, 0, 0)
+}
+
+// gatherValues collects c with a pedantic registry and returns the value
+// of every sample keyed as name{label="value",...}, with labels sorted by
+// name. Summaries are keyed by their _count and _sum samples.
+func gatherValues(tb testing.TB, c prometheus.Collector) map[string]float64 {
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+
+	families, err := reg.Gather()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			var labels []string
+			for _, label := range metric.GetLabel() {
+				labels = append(labels, fmt.Sprintf("%s=%q", label.GetName(), label.GetValue()))
+			}
+			key := "{" + strings.Join(labels, ",") + "}"
+
+			switch {
+			case metric.GetSummary() != nil:
+				values[family.GetName()+"_count"+key] = float64(metric.GetSummary().GetSampleCount())
+				values[family.GetName()+"_sum"+key] = metric.GetSummary().GetSampleSum()
+			case metric.GetCounter() != nil:
+				values[family.GetName()+key] = metric.GetCounter().GetValue()
+			case metric.GetGauge() != nil:
+				values[family.GetName()+key] = metric.GetGauge().GetValue()
+			default:
+				values[family.GetName()+key] = metric.GetUntyped().GetValue()
+			}
+		}
+	}
+	return values
+}
+
+// checkValues fails tb for every sample of want missing from or differing
+// in got, and for every sample of got named in absent.
+func checkValues(tb testing.TB, got, want map[string]float64, absent ...string) {
+	tb.Helper()
+
+	for key, w := range want {
+		g, ok := got[key]
+		if !ok {
+			tb.Errorf("missing %s", key)
+			continue
+		}
+		if g != w {
+			tb.Errorf("%s: got %v, want %v", key, g, w)
+		}
+	}
+	for _, prefix := range absent {
+		for key := range got {
+			if strings.HasPrefix(key, prefix) {
+				tb.Errorf("unexpected %s", key)
+			}
+		}
+	}
+}
+
+// readRegionsFixture returns the sub=Regions response in testdata.
+func readRegionsFixture(tb testing.TB) []byte {
+	bts, err := ioutil.ReadFile("testdata/regions.json")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return bts
+}
+
+// scaleRegionsFixture repeats the region attributes of the sub=Regions
+// fixture copies times under distinct region names, to benchmark responses
+// of regionservers with thousands of regions.
+func scaleRegionsFixture(tb testing.TB, copies int) []byte {
+	var head, attrs, tail []string
+	for _, line := range strings.Split(string(readRegionsFixture(tb)), "\n") {
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(line), `"Namespace_`):
+			attrs = append(attrs, strings.TrimSuffix(line, ","))
+		case attrs == nil:
+			head = append(head, line)
+		default:
+			tail = append(tail, line)
+		}
+	}
+
+	var scaled []string
+	for i := 0; i < copies; i++ {
+		for _, attr := range attrs {
+			scaled = append(scaled, strings.Replace(attr, "_region_", fmt.Sprintf("_region_%05d", i), 1))
+		}
+	}
+
+	return []byte(strings.Join(head, "\n") + "\n" + strings.Join(scaled, ",\n") + "\n" + strings.Join(tail, "\n"))
+}
+
+func TestStreamFirstBean(t *testing.T) {
+	fixture := readRegionsFixture(t)
+	jmx := NewJmxClient(newJmxTestServer(t, fixture), 0, 0)
+
+	want := gjson.GetBytes(fixture, "beans.0").Map()
+	got := map[string]gjson.Result{}
+	err := streamFirstBean(log.NewNopLogger(), jmx, regionsQry, func(attr string, v gjson.Result) {
+		got[attr] = v
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("got %d attributes, want %d", len(got), len(want))
+	}
+	for attr, w := range want {
+		g, ok := got[attr]
+		if !ok {
+			t.Errorf("missing attribute %s", attr)
+			continue
+		}
+		if g.Type != w.Type || g.String() != w.String() || g.Float() != w.Float() {
+			t.Errorf("attribute %s: got %v, want %v", attr, g, w)
+		}
+	}
+}
+
+func TestStreamFirstBeanSkipsNestedAttributes(t *testing.T) {
+	body := `{
+  "version" : { "skipped" : [ 1, 2 ] },
+  "beans" : [ {
+    "name" : "Hadoop:service=HBase,name=RegionServer,sub=Server",
+    "object" : { "a" : { "b" : [ 1, { "c" : "}" } ] } },
+    "before" : 1,
+    "array" : [ [ ], [ { } ], [ 1, [ 2, [ 3 ] ] ] ],
+    "after" : "x",
+    "empty" : { }
+  }, {
+    "name" : "Hadoop:service=HBase,name=RegionServer,sub=Regions"
+  } ]
+}`
+	jmx := NewJmxClient(newJmxTestServer(t, []byte(body)), 0, 0)
+
+	var got []string
+	err := streamFirstBean(log.NewNopLogger(), jmx, regionsQry, func(attr string, v gjson.Result) {
+		got = append(got, attr+"="+v.String())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "name=Hadoop:service=HBase,name=RegionServer,sub=Server before=1 after=x"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestStreamFirstBeanNoBeans(t *testing.T) {
+	for _, body := range []string{`{"beans":[]}`, `{}`} {
+		jmx := NewJmxClient(newJmxTestServer(t, []byte(body)), 0, 0)
+
+		err := streamFirstBean(log.NewNopLogger(), jmx, regionsQry, func(string, gjson.Result) {})
+		if err != errNoBeans {
+			t.Errorf("%s: got %v, want %v", body, err, errNoBeans)
+		}
+	}
+}
+
+func TestSkipValue(t *testing.T) {
+	for _, value := range []string{
+		`{}`,
+		`[]`,
+		`{"a":{"b":{"c":1}}}`,
+		`[[1,[2,[3]]],{"a":[]}]`,
+		`[{"a":[1,2]},{"b":{}},null,true,"]"]`,
+		`{"a":"}","b":["{","["]}`,
+	} {
+		dec := json.NewDecoder(strings.NewReader(`{"skipped":` + value + `,"next":1}`))
+		if err := expectDelim(dec, '{'); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := dec.Token(); err != nil {
+			t.Fatal(err)
+		}
+		token, err := dec.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := skipValue(dec, token.(json.Delim)); err != nil {
+			t.Errorf("%s: %v", value, err)
+			continue
+		}
+		if token, err := dec.Token(); token != "next" {
+			t.Errorf("%s: got token %v (%v) after the skipped value, want next", value, token, err)
+		}
+	}
+}
+
+func TestSkipValueTruncated(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`[1,{"a":[2,{}]`))
+	if err := expectDelim(dec, '['); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := skipValue(dec, '['); err == nil {
+		t.Error("got no error for a truncated array")
+	}
+}
+
+func TestJmxMaxResponseSize(t *testing.T) {
+	fixture := readRegionsFixture(t)
+	size := int64(len(fixture))
+
+	for _, test := range []struct {
+		name     string
+		limit    int64
+		tooLarge bool
+	}{
+		{"unlimited", 0, false},
+		{"larger than the response", size + 1, false},
+		{"exactly the response", size, false},
+		{"one byte short of the response", size - 1, true},
+		{"half of the response", size / 2, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			jmx := NewJmxClient(newJmxTestServer(t, fixture), test.limit, 0)
+
+			bts, err := fetchJmx(log.NewNopLogger(), jmx, regionsQry)
+			if test.tooLarge {
+				if !errors.Is(err, errJmxResponseTooLarge) {
+					t.Fatalf("got %v, want %v", err, errJmxResponseTooLarge)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(bts) != string(fixture) {
+				t.Errorf("got %d bytes, want the %d bytes of the response", len(bts), size)
+			}
+		})
+	}
+}
+
+func TestStreamFirstBeanMaxResponseSize(t *testing.T) {
+	fixture := readRegionsFixture(t)
+	jmx := NewJmxClient(newJmxTestServer(t, fixture), int64(len(fixture)/2), 0)
+
+	err := streamFirstBean(log.NewNopLogger(), jmx, regionsQry, func(string, gjson.Result) {})
+	if !errors.Is(err, errJmxResponseTooLarge) {
+		t.Errorf("got %v, want %v", err, errJmxResponseTooLarge)
+	}
+}
+
+func TestJmxReadFailureCountsAsParseFailure(t *testing.T) {
+	// Every response of the servlet exceeds the maximum size of the client.
+	jmx := NewJmxClient(newJmxTestServer(t, readRegionsFixture(t)), 16, 0)
+	logger := log.NewNopLogger()
+
+	for _, test := range []struct {
+		name      string
+		collector prometheus.Collector
+		subsystem string
+	}{
+		{"jvm", NewHBaseJvm(logger, jmx), "jvm"},
+		{"master server", NewMasterServer(logger, jmx), "server"},
+		{"regionserver server", NewRsServer(logger, jmx), "server"},
+		{"wal", NewRsWal(logger, jmx), "wal"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := gatherValues(t, test.collector)
+			checkValues(t, got, map[string]float64{
+				"hbase_" + test.subsystem + "_up{}":                  0,
+				"hbase_" + test.subsystem + "_json_parse_failures{}": 1,
+			})
+		})
+	}
+}
+
+// BenchmarkDecodeRegions compares streaming the sub=Regions bean with
+// reading the whole response and decoding it with gjson, as RsRegion did
+// before.
+func BenchmarkDecodeRegions(b *testing.B) {
+	for _, copies := range []int{1, 600} {
+		body := scaleRegionsFixture(b, copies)
+		jmx := NewJmxClient(newJmxTestServer(b, body), 0, 0)
+		logger := log.NewNopLogger()
+
+		b.Run(fmt.Sprintf("regions=%d/stream", 5*copies), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for i := 0; i < b.N; i++ {
+				attrs := 0
+				err := streamFirstBean(logger, jmx, regionsQry, func(attr string, v gjson.Result) {
+					if strings.HasPrefix(attr, "Namespace") {
+						attrs++
+					}
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("regions=%d/gjson", 5*copies), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for i := 0; i < b.N; i++ {
+				bts, err := fetchJmx(logger, jmx, regionsQry)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				attrs := 0
+				for attr := range gjson.Get(string(bts), "beans").Array()[0].Map() {
+					if strings.HasPrefix(attr, "Namespace") {
+						attrs++
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestJmxTimeout(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	u, err := url.Parse(srv.URL + "/jmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jmx := NewJmxClient(u, 0, 100*time.Millisecond)
+
+	begin := time.Now()
+	if _, err := fetchJmx(log.NewNopLogger(), jmx, regionsQry); err == nil {
+		t.Fatal("got no error from a hanging jmx servlet")
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("request took %s, want it to time out after 100ms", elapsed)
+	}
+}
@@ -2,10 +2,6 @@ package collector
 
 import (
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
 	"strings"
 
 	"github.com/go-kit/kit/log"
@@ -37,7 +33,7 @@ type hbaseJvmMetric struct {
 
 type HBaseJvm struct {
 	logger log.Logger
-	url    *url.URL
+	jmx    *JmxClient
 
 	up                              prometheus.Gauge
 	totalScrapes, jsonParseFailures prometheus.Counter
@@ -45,12 +41,12 @@ type HBaseJvm struct {
 	metrics []*hbaseJvmMetric
 }
 
-func NewHBaseJvm(logger log.Logger, url *url.URL) *HBaseJvm {
+func NewHBaseJvm(logger log.Logger, jmx *JmxClient) *HBaseJvm {
 	subsystem := "jvm"
 
 	return &HBaseJvm{
 		logger: logger,
-		url:    url,
+		jmx:    jmx,
 
 		up: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
@@ -154,6 +150,10 @@ func NewHBaseJvm(logger log.Logger, url *url.URL) *HBaseJvm {
 	}
 }
 
+func (m *HBaseJvm) upGauge() prometheus.Gauge {
+	return m.up
+}
+
 func (m *HBaseJvm) Describe(ch chan<- *prometheus.Desc) {
 	for _, metric := range m.metrics {
 		ch <- metric.Desc
@@ -167,32 +167,11 @@ func (m *HBaseJvm) Describe(ch chan<- *prometheus.Desc) {
 func (m *HBaseJvm) fetchAndDecodeHBaseJvm() (hbaseJvmResponse, error) {
 	var mjr hbaseJvmResponse
 
-	u := *m.url
-	url := u.String() + "?" + "qry=Hadoop:service=HBase,name=JvmMetrics"
-	res, err := http.Get(url)
-
+	bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name=JvmMetrics")
 	if err != nil {
-		return mjr, fmt.Errorf("failed to get cluster health from %s://%s:%s%s: %s",
-			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
-	}
-
-	defer func() {
-		err = res.Body.Close()
-		if err != nil {
-			_ = level.Warn(m.logger).Log(
-				"msg", "failed to close http.Client",
-				"err", err,
-			)
+		if isJmxReadError(err) {
+			m.jsonParseFailures.Inc()
 		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return mjr, fmt.Errorf("HTTP Request failed with code %d", res.StatusCode)
-	}
-
-	bts, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		m.jsonParseFailures.Inc()
 		return mjr, err
 	}
 
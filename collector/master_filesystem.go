@@ -0,0 +1,150 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseMasterFileSystemLabels      = []string{"host", "role"}
+	defaultHBaseMasterFileSystemLabelValues = func(masterFileSystem masterFileSystemResponse) []string {
+		return []string{
+			masterFileSystem.Host,
+			strings.ToLower(masterFileSystem.Role),
+		}
+	}
+)
+
+// MasterFileSystem collects the WAL splitting metrics of a master, which
+// dominate the recovery time of a crashed regionserver.
+type MasterFileSystem struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	histograms []*hbaseHistogram
+}
+
+func NewMasterFileSystem(logger log.Logger, jmx *JmxClient) *MasterFileSystem {
+	subsystem := "filesystem"
+
+	return &MasterFileSystem{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase filesystem endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase filesystem scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		histograms: []*hbaseHistogram{
+			{
+				Attr: "HlogSplitTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "hlog_split_time_ms"),
+					"The time to split the WAL files of a crashed regionserver in milliseconds.",
+					defaultHBaseMasterFileSystemLabels, nil,
+				),
+			},
+			{
+				Attr: "HlogSplitSize",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "hlog_split_size_bytes"),
+					"The size of the WAL files split for a crashed regionserver in bytes.",
+					defaultHBaseMasterFileSystemLabels, nil,
+				),
+			},
+			{
+				Attr: "MetaHlogSplitTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "meta_hlog_split_time_ms"),
+					"The time to split the hbase:meta WAL files in milliseconds.",
+					defaultHBaseMasterFileSystemLabels, nil,
+				),
+			},
+			{
+				Attr: "MetaHlogSplitSize",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "meta_hlog_split_size_bytes"),
+					"The size of the hbase:meta WAL files split in bytes.",
+					defaultHBaseMasterFileSystemLabels, nil,
+				),
+			},
+		},
+	}
+}
+
+func (m *MasterFileSystem) upGauge() prometheus.Gauge {
+	return m.up
+}
+
+func (m *MasterFileSystem) Describe(ch chan<- *prometheus.Desc) {
+	for _, histogram := range m.histograms {
+		ch <- histogram.Desc
+	}
+
+	ch <- m.up.Desc()
+	ch <- m.totalScrapes.Desc()
+	ch <- m.jsonParseFailures.Desc()
+}
+
+func (m *MasterFileSystem) Collect(ch chan<- prometheus.Metric) {
+	m.totalScrapes.Inc()
+	defer func() {
+		ch <- m.up
+		ch <- m.totalScrapes
+		ch <- m.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name=Master,sub=FileSystem")
+	if err != nil {
+		if isJmxReadError(err) {
+			m.jsonParseFailures.Inc()
+		}
+		m.up.Set(0)
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to fetch filesystem metrics",
+			"err", err,
+		)
+		return
+	}
+
+	bean, err := firstBean(bts)
+	if err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode filesystem metrics",
+			"err", err,
+		)
+		return
+	}
+
+	var masterFileSystemResp masterFileSystemResponse
+	if err := json.Unmarshal([]byte(bean.Raw), &masterFileSystemResp); err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode filesystem metrics",
+			"err", err,
+		)
+		return
+	}
+	m.up.Set(1)
+
+	collectHistograms(ch, m.histograms, bean.Map(), defaultHBaseMasterFileSystemLabelValues(masterFileSystemResp)...)
+}
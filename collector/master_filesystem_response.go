@@ -0,0 +1,6 @@
+package collector
+
+type masterFileSystemResponse struct {
+	Host string `json:"tag.Hostname"`
+	Role string `json:"tag.Context"`
+}
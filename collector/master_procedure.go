@@ -0,0 +1,239 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	defaultHBaseMasterProcedureLabels      = []string{"host", "role"}
+	defaultHBaseMasterProcedureLabelValues = func(masterProcedure masterProcedureResponse) []string {
+		return []string{
+			masterProcedure.Host,
+			strings.ToLower(masterProcedure.Role),
+		}
+	}
+)
+
+// hbaseMasterProcedureBeans are the sub-beans of a master publishing
+// procedure metrics: the ServerCrash procedures in Server, the region
+// procedures in AssignmentManager and numMasterWALs in Procedure.
+var hbaseMasterProcedureBeans = []string{"Server", "AssignmentManager", "Procedure"}
+
+// MasterProcedure collects the procedure executor metrics of a master. Every
+// procedure type publishes <Type>SubmittedCount, <Type>FailedCount and a
+// <Type>Time histogram, e.g. ServerCrash in the Server bean or Assign in the
+// AssignmentManager bean, so the types are discovered from those beans
+// instead of being listed here.
+type MasterProcedure struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	submitted  *prometheus.Desc
+	failed     *prometheus.Desc
+	pending    *prometheus.Desc
+	time       *prometheus.Desc
+	masterWals *prometheus.Desc
+}
+
+func NewMasterProcedure(logger log.Logger, jmx *JmxClient) *MasterProcedure {
+	subsystem := "procedure"
+	procedureLabels := append(defaultHBaseMasterProcedureLabels, "procedure")
+
+	return &MasterProcedure{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase procedure endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase procedure scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		submitted: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "submitted_count"),
+			"The number of procedures submitted, by procedure type.",
+			procedureLabels, nil,
+		),
+		failed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "failed_count"),
+			"The number of procedures that failed, by procedure type.",
+			procedureLabels, nil,
+		),
+		pending: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "pending"),
+			"The number of procedures submitted but not completed yet, by procedure type.",
+			procedureLabels, nil,
+		),
+		time: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "time_ms"),
+			"The time of completed procedures in milliseconds, by procedure type.",
+			procedureLabels, nil,
+		),
+		masterWals: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "master_wals"),
+			"The number of procedure WAL files of the master.",
+			defaultHBaseMasterProcedureLabels, nil,
+		),
+	}
+}
+
+func (m *MasterProcedure) upGauge() prometheus.Gauge {
+	return m.up
+}
+
+func (m *MasterProcedure) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.submitted
+	ch <- m.failed
+	ch <- m.pending
+	ch <- m.time
+	ch <- m.masterWals
+
+	ch <- m.up.Desc()
+	ch <- m.totalScrapes.Desc()
+	ch <- m.jsonParseFailures.Desc()
+}
+
+func (m *MasterProcedure) Collect(ch chan<- prometheus.Metric) {
+	m.totalScrapes.Inc()
+	defer func() {
+		ch <- m.up
+		ch <- m.totalScrapes
+		ch <- m.jsonParseFailures
+	}()
+
+	var beans []gjson.Result
+	for _, sub := range hbaseMasterProcedureBeans {
+		bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name=Master,sub="+sub)
+		if err != nil {
+			if isJmxReadError(err) {
+				m.jsonParseFailures.Inc()
+			}
+			m.up.Set(0)
+			_ = level.Warn(m.logger).Log(
+				"msg", "failed to fetch procedure metrics",
+				"bean", sub,
+				"err", err,
+			)
+			return
+		}
+
+		// Older masters publish no AssignmentManager or Procedure bean.
+		subBeans, err := allBeans(bts)
+		if err == errNoBeans {
+			continue
+		}
+		if err != nil {
+			m.up.Set(0)
+			m.jsonParseFailures.Inc()
+			_ = level.Warn(m.logger).Log(
+				"msg", "failed to decode procedure metrics",
+				"bean", sub,
+				"err", err,
+			)
+			return
+		}
+		beans = append(beans, subBeans...)
+	}
+	m.up.Set(1)
+
+	// A type published by more than one bean is exported once, from the
+	// first of hbaseMasterProcedureBeans, as the same metric twice would
+	// fail the scrape.
+	seen := map[string]bool{}
+	for _, bean := range beans {
+		var masterProcedureResp masterProcedureResponse
+		if err := json.Unmarshal([]byte(bean.Raw), &masterProcedureResp); err != nil {
+			m.jsonParseFailures.Inc()
+			_ = level.Warn(m.logger).Log(
+				"msg", "failed to decode procedure metrics",
+				"bean", bean.Get("name").String(),
+				"err", err,
+			)
+			continue
+		}
+
+		labels := defaultHBaseMasterProcedureLabelValues(masterProcedureResp)
+		attrs := bean.Map()
+
+		if v, ok := attrs["numMasterWALs"]; ok && !seen["numMasterWALs"] {
+			seen["numMasterWALs"] = true
+			ch <- prometheus.MustNewConstMetric(
+				m.masterWals,
+				prometheus.GaugeValue,
+				v.Float(),
+				labels...,
+			)
+		}
+
+		for attr, submitted := range attrs {
+			if !strings.HasSuffix(attr, "SubmittedCount") {
+				continue
+			}
+
+			prefix := strings.TrimSuffix(attr, "SubmittedCount")
+			if seen[prefix] {
+				continue
+			}
+			seen[prefix] = true
+			procedureLabels := append(labels, snakeCase(prefix))
+
+			ch <- prometheus.MustNewConstMetric(
+				m.submitted,
+				prometheus.CounterValue,
+				submitted.Float(),
+				procedureLabels...,
+			)
+
+			if failed, ok := attrs[prefix+"FailedCount"]; ok {
+				ch <- prometheus.MustNewConstMetric(
+					m.failed,
+					prometheus.CounterValue,
+					failed.Float(),
+					procedureLabels...,
+				)
+			}
+
+			count, sum, quantiles, ok := histogramSummary(attrs, prefix+"Time")
+			if !ok {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstSummary(
+				m.time,
+				count,
+				sum,
+				quantiles,
+				procedureLabels...,
+			)
+
+			// The time histogram is updated once per finished procedure,
+			// failed or not, so the difference is what is still running.
+			pending := submitted.Float() - float64(count)
+			if pending < 0 {
+				pending = 0
+			}
+			ch <- prometheus.MustNewConstMetric(
+				m.pending,
+				prometheus.GaugeValue,
+				pending,
+				procedureLabels...,
+			)
+		}
+	}
+}
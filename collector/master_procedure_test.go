@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestMasterProcedure(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		fixtures map[string]string
+		want     map[string]float64
+		absent   []string
+	}{
+		{
+			// ServerCrash is published by both the Server and the
+			// AssignmentManager fixture and must be exported once, from
+			// the Server bean.
+			name: "all beans",
+			fixtures: map[string]string{
+				"Hadoop:service=HBase,name=Master,sub=Server":            "master_procedure_server.json",
+				"Hadoop:service=HBase,name=Master,sub=AssignmentManager": "master_procedure_assignment.json",
+				"Hadoop:service=HBase,name=Master,sub=Procedure":         "master_procedure.json",
+			},
+			want: map[string]float64{
+				`hbase_procedure_up{}`: 1,
+				`hbase_procedure_submitted_count{host="master1.example.com",procedure="server_crash",role="master"}`: 4,
+				`hbase_procedure_failed_count{host="master1.example.com",procedure="server_crash",role="master"}`:    1,
+				`hbase_procedure_time_ms_count{host="master1.example.com",procedure="server_crash",role="master"}`:   3,
+				`hbase_procedure_time_ms_sum{host="master1.example.com",procedure="server_crash",role="master"}`:     1500,
+				`hbase_procedure_pending{host="master1.example.com",procedure="server_crash",role="master"}`:         1,
+				`hbase_procedure_submitted_count{host="master1.example.com",procedure="assign",role="master"}`:       120,
+				`hbase_procedure_failed_count{host="master1.example.com",procedure="assign",role="master"}`:          2,
+				`hbase_procedure_time_ms_count{host="master1.example.com",procedure="assign",role="master"}`:         118,
+				`hbase_procedure_time_ms_sum{host="master1.example.com",procedure="assign",role="master"}`:           4720,
+				`hbase_procedure_pending{host="master1.example.com",procedure="assign",role="master"}`:               2,
+				// More completed than submitted moves clamp to 0 pending.
+				`hbase_procedure_pending{host="master1.example.com",procedure="move",role="master"}`: 0,
+				`hbase_procedure_master_wals{host="master1.example.com",role="master"}`:              2,
+			},
+		},
+		{
+			// Older masters publish no AssignmentManager or Procedure bean.
+			name: "server bean only",
+			fixtures: map[string]string{
+				"Hadoop:service=HBase,name=Master,sub=Server": "master_procedure_server.json",
+			},
+			want: map[string]float64{
+				`hbase_procedure_up{}`: 1,
+				`hbase_procedure_submitted_count{host="master1.example.com",procedure="server_crash",role="master"}`: 4,
+				`hbase_procedure_pending{host="master1.example.com",procedure="server_crash",role="master"}`:         1,
+			},
+			absent: []string{
+				`hbase_procedure_submitted_count{host="master1.example.com",procedure="assign",`,
+				"hbase_procedure_master_wals",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			jmx := newJmxFixtureClient(t, test.fixtures)
+
+			got := gatherValues(t, NewMasterProcedure(log.NewNopLogger(), jmx))
+			checkValues(t, got, test.want, test.absent...)
+		})
+	}
+}
@@ -2,10 +2,6 @@ package collector
 
 import (
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
 	"strings"
 
 	"github.com/go-kit/kit/log"
@@ -33,7 +29,7 @@ type masterServerMetric struct {
 
 type MasterServer struct {
 	logger log.Logger
-	url    *url.URL
+	jmx    *JmxClient
 
 	up                              prometheus.Gauge
 	totalScrapes, jsonParseFailures prometheus.Counter
@@ -41,12 +37,12 @@ type MasterServer struct {
 	metrics []*masterServerMetric
 }
 
-func NewMasterServer(logger log.Logger, url *url.URL) *MasterServer {
+func NewMasterServer(logger log.Logger, jmx *JmxClient) *MasterServer {
 	subsystem := "server"
 
 	return &MasterServer{
 		logger: logger,
-		url:    url,
+		jmx:    jmx,
 
 		up: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
@@ -114,10 +110,86 @@ func NewMasterServer(logger log.Logger, url *url.URL) *MasterServer {
 				},
 				Labels: defaultHBaseMasterServerLabelServerValues,
 			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "num_draining_regionservers"),
+					"The number of draining regionservers, including decommissioned ones.",
+					defaultHBaseMasterServerLabels, nil,
+				),
+				Value: func(masterServer masterServerResponse) float64 {
+					return masterServer.NumDrainingRegionServers
+				},
+				Labels: defaultHBaseMasterServerLabelServerValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "cluster_requests"),
+					"The number of requests served by the whole cluster.",
+					defaultHBaseMasterServerLabels, nil,
+				),
+				Value: func(masterServer masterServerResponse) float64 {
+					return masterServer.ClusterRequests
+				},
+				Labels: defaultHBaseMasterServerLabelServerValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "master_active_time_seconds"),
+					"The unix time the master became active in seconds.",
+					defaultHBaseMasterServerLabels, nil,
+				),
+				Value: func(masterServer masterServerResponse) float64 {
+					return masterServer.MasterActiveTime / 1000
+				},
+				Labels: defaultHBaseMasterServerLabelServerValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "master_start_time_seconds"),
+					"The unix time the master started in seconds.",
+					defaultHBaseMasterServerLabels, nil,
+				),
+				Value: func(masterServer masterServerResponse) float64 {
+					return masterServer.MasterStartTime / 1000
+				},
+				Labels: defaultHBaseMasterServerLabelServerValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "merge_plan_count"),
+					"The number of region merges planned by the normalizer.",
+					defaultHBaseMasterServerLabels, nil,
+				),
+				Value: func(masterServer masterServerResponse) float64 {
+					return masterServer.MergePlanCount
+				},
+				Labels: defaultHBaseMasterServerLabelServerValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "split_plan_count"),
+					"The number of region splits planned by the normalizer.",
+					defaultHBaseMasterServerLabels, nil,
+				),
+				Value: func(masterServer masterServerResponse) float64 {
+					return masterServer.SplitPlanCount
+				},
+				Labels: defaultHBaseMasterServerLabelServerValues,
+			},
 		},
 	}
 }
 
+func (m *MasterServer) upGauge() prometheus.Gauge {
+	return m.up
+}
+
 func (m *MasterServer) Describe(ch chan<- *prometheus.Desc) {
 	for _, metric := range m.metrics {
 		ch <- metric.Desc
@@ -131,32 +203,11 @@ func (m *MasterServer) Describe(ch chan<- *prometheus.Desc) {
 func (m *MasterServer) fetchAndDecodeMasterServer() (masterServerResponse, error) {
 	var msr masterServerResponse
 
-	u := *m.url
-	url := u.String() + "?" + "qry=Hadoop:service=HBase,name=Master,sub=Server"
-	res, err := http.Get(url)
-
+	bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name=Master,sub=Server")
 	if err != nil {
-		return msr, fmt.Errorf("failed to get cluster health from %s://%s:%s%s: %s",
-			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
-	}
-
-	defer func() {
-		err = res.Body.Close()
-		if err != nil {
-			_ = level.Warn(m.logger).Log(
-				"msg", "failed to close http.Client",
-				"err", err,
-			)
+		if isJmxReadError(err) {
+			m.jsonParseFailures.Inc()
 		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return msr, fmt.Errorf("HTTP Request failed with code %d", res.StatusCode)
-	}
-
-	bts, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		m.jsonParseFailures.Inc()
 		return msr, err
 	}
 
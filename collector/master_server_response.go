@@ -1,10 +1,16 @@
 package collector
 
 type masterServerResponse struct {
-	Host                 string  `json:"tag.Hostname"`
-	Role                 string  `json:"tag.Context"`
-	NumRegionServers     int     `json:"numRegionServers"`
-	NumDeadRegionServers int     `json:"numDeadRegionServers"`
-	IsActiveMaster       string  `json:"tag.isActiveMaster"`
-	AverageLoad          float64 `json:"averageLoad"`
+	Host                     string  `json:"tag.Hostname"`
+	Role                     string  `json:"tag.Context"`
+	NumRegionServers         int     `json:"numRegionServers"`
+	NumDeadRegionServers     int     `json:"numDeadRegionServers"`
+	IsActiveMaster           string  `json:"tag.isActiveMaster"`
+	AverageLoad              float64 `json:"averageLoad"`
+	NumDrainingRegionServers float64 `json:"numDrainingRegionServers"`
+	ClusterRequests          float64 `json:"clusterRequests"`
+	MasterActiveTime         float64 `json:"masterActiveTime"`
+	MasterStartTime          float64 `json:"masterStartTime"`
+	MergePlanCount           float64 `json:"mergePlanCount"`
+	SplitPlanCount           float64 `json:"splitPlanCount"`
 }
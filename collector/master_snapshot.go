@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseMasterSnapshotLabels      = []string{"host", "role"}
+	defaultHBaseMasterSnapshotLabelValues = func(masterSnapshot masterSnapshotResponse) []string {
+		return []string{
+			masterSnapshot.Host,
+			strings.ToLower(masterSnapshot.Role),
+		}
+	}
+)
+
+// MasterSnapshot collects the snapshot metrics of a master. The count of
+// each summary is the number of snapshots taken, restored or cloned.
+type MasterSnapshot struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	histograms []*hbaseHistogram
+}
+
+func NewMasterSnapshot(logger log.Logger, jmx *JmxClient) *MasterSnapshot {
+	subsystem := "snapshot"
+
+	return &MasterSnapshot{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase snapshot endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase snapshot scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		histograms: []*hbaseHistogram{
+			{
+				Attr: "snapshotTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "time_ms"),
+					"The time to take a snapshot in milliseconds.",
+					defaultHBaseMasterSnapshotLabels, nil,
+				),
+			},
+			{
+				Attr: "snapshotRestoreTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "restore_time_ms"),
+					"The time to restore a table from a snapshot in milliseconds.",
+					defaultHBaseMasterSnapshotLabels, nil,
+				),
+			},
+			{
+				Attr: "snapshotCloneTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "clone_time_ms"),
+					"The time to clone a snapshot into a new table in milliseconds.",
+					defaultHBaseMasterSnapshotLabels, nil,
+				),
+			},
+		},
+	}
+}
+
+func (m *MasterSnapshot) upGauge() prometheus.Gauge {
+	return m.up
+}
+
+func (m *MasterSnapshot) Describe(ch chan<- *prometheus.Desc) {
+	for _, histogram := range m.histograms {
+		ch <- histogram.Desc
+	}
+
+	ch <- m.up.Desc()
+	ch <- m.totalScrapes.Desc()
+	ch <- m.jsonParseFailures.Desc()
+}
+
+func (m *MasterSnapshot) Collect(ch chan<- prometheus.Metric) {
+	m.totalScrapes.Inc()
+	defer func() {
+		ch <- m.up
+		ch <- m.totalScrapes
+		ch <- m.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name=Master,sub=Snapshots")
+	if err != nil {
+		if isJmxReadError(err) {
+			m.jsonParseFailures.Inc()
+		}
+		m.up.Set(0)
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to fetch snapshot metrics",
+			"err", err,
+		)
+		return
+	}
+
+	bean, err := firstBean(bts)
+	if err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode snapshot metrics",
+			"err", err,
+		)
+		return
+	}
+
+	var masterSnapshotResp masterSnapshotResponse
+	if err := json.Unmarshal([]byte(bean.Raw), &masterSnapshotResp); err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode snapshot metrics",
+			"err", err,
+		)
+		return
+	}
+	m.up.Set(1)
+
+	collectHistograms(ch, m.histograms, bean.Map(), defaultHBaseMasterSnapshotLabelValues(masterSnapshotResp)...)
+}
@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Poller collects each of its collectors in a goroutine of its own, every
+// interval, so a scrape never waits for JMX. Scrapes get the metrics of the
+// last poll with its time as timestamp. Metrics polled longer than maxAge ago
+// are not exported at all, so dashboards show a gap instead of a flat line
+// when a process stops answering.
+type Poller struct {
+	interval time.Duration
+	maxAge   time.Duration
+	entries  []*pollerEntry
+
+	age *prometheus.Desc
+}
+
+type pollerEntry struct {
+	name      string
+	collector prometheus.Collector
+
+	mu      sync.Mutex
+	metrics []prometheus.Metric
+	polled  time.Time
+}
+
+func NewPoller(interval, maxAge time.Duration) *Poller {
+	return &Poller{
+		interval: interval,
+		maxAge:   maxAge,
+
+		age: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "exporter", "poll_age_seconds"),
+			"The time since the last poll of the collector in seconds.",
+			[]string{"collector"}, nil,
+		),
+	}
+}
+
+// Add polls collector, exported as the collector label of
+// hbase_exporter_poll_age_seconds. Add must not be called once the poller
+// is started.
+func (p *Poller) Add(name string, collector prometheus.Collector) {
+	p.entries = append(p.entries, &pollerEntry{
+		name:      name,
+		collector: collector,
+	})
+}
+
+// Start polls every collector immediately and then every interval. A poll
+// slower than the interval delays the next one rather than overlapping it.
+func (p *Poller) Start() {
+	for _, entry := range p.entries {
+		go func(entry *pollerEntry) {
+			ticker := time.NewTicker(p.interval)
+			defer ticker.Stop()
+
+			for {
+				entry.poll()
+				<-ticker.C
+			}
+		}(entry)
+	}
+}
+
+func (p *Poller) Describe(ch chan<- *prometheus.Desc) {
+	for _, entry := range p.entries {
+		entry.collector.Describe(ch)
+	}
+
+	ch <- p.age
+}
+
+func (p *Poller) Collect(ch chan<- prometheus.Metric) {
+	for _, entry := range p.entries {
+		metrics, polled := entry.snapshot()
+		if polled.IsZero() {
+			continue
+		}
+
+		age := time.Since(polled)
+		ch <- prometheus.MustNewConstMetric(
+			p.age,
+			prometheus.GaugeValue,
+			age.Seconds(),
+			entry.name,
+		)
+
+		if age > p.maxAge {
+			continue
+		}
+		for _, metric := range metrics {
+			ch <- prometheus.NewMetricWithTimestamp(polled, metric)
+		}
+	}
+}
+
+func (e *pollerEntry) poll() {
+	polled := time.Now()
+	metrics := collectMetrics(e.collector)
+
+	e.mu.Lock()
+	e.metrics = metrics
+	e.polled = polled
+	e.mu.Unlock()
+}
+
+func (e *pollerEntry) snapshot() ([]prometheus.Metric, time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.metrics, e.polled
+}
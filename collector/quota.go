@@ -0,0 +1,303 @@
+package collector
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseQuotaLabels = []string{"host", "role"}
+
+	// hbaseQuotaChores maps the chore time histograms of the quota beans to
+	// the chore label.
+	hbaseQuotaChores = map[string]string{
+		"quotaObserverChoreTime":              "quota_observer",
+		"snapshotObserverChoreTime":           "snapshot_observer",
+		"snapshotObserverSizeComputationTime": "snapshot_observer_size_computation",
+		"snapshotObserverSnapshotFetchTime":   "snapshot_observer_snapshot_fetch",
+		"fileSystemUtilizationChoreTime":      "file_system_utilization",
+		"spaceQuotaRefresherChoreTime":        "space_quota_refresher",
+		"regionSizeReportingChoreTime":        "region_size_reporting",
+	}
+)
+
+type hbaseQuotaMetric struct {
+	Type prometheus.ValueType
+	Desc *prometheus.Desc
+}
+
+// hbaseSpaceQuota is one entry of the space quota snapshots the master
+// publishes as a tag, e.g. [{table=ns:t1, usage=1024, limit=4096}].
+type hbaseSpaceQuota struct {
+	Name  string
+	Usage float64
+	Limit float64
+}
+
+// HBaseQuota collects the space quota metrics of a master or regionserver.
+type HBaseQuota struct {
+	logger  log.Logger
+	jmx     *JmxClient
+	service string
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	metrics             map[string]*hbaseQuotaMetric
+	choreTime           *prometheus.Desc
+	tableSpaceUsage     *prometheus.Desc
+	tableSpaceLimit     *prometheus.Desc
+	namespaceSpaceUsage *prometheus.Desc
+	namespaceSpaceLimit *prometheus.Desc
+}
+
+// NewHBaseQuota returns a collector for the quota beans of service, which is
+// either MasterService or RegionServerService.
+func NewHBaseQuota(logger log.Logger, jmx *JmxClient, service string) *HBaseQuota {
+	subsystem := "quota"
+	newMetric := func(valueType prometheus.ValueType, name, help string) *hbaseQuotaMetric {
+		return &hbaseQuotaMetric{
+			Type: valueType,
+			Desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, name),
+				help,
+				defaultHBaseQuotaLabels, nil,
+			),
+		}
+	}
+	tablesInViolation := newMetric(prometheus.GaugeValue, "tables_in_violation", "The number of tables violating their space quota.")
+
+	return &HBaseQuota{
+		logger:  logger,
+		jmx:     jmx,
+		service: service,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase quota endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase quota scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		metrics: map[string]*hbaseQuotaMetric{
+			"numSpaceQuotas":               newMetric(prometheus.GaugeValue, "space_quotas", "The number of space quotas defined."),
+			"numTablesInQuotaViolation":    tablesInViolation,
+			"numTablesInViolation":         tablesInViolation,
+			"numNamespaceInQuotaViolation": newMetric(prometheus.GaugeValue, "namespaces_in_violation", "The number of namespaces violating their space quota."),
+			"numRegionSizeReports":         newMetric(prometheus.GaugeValue, "region_size_reports", "The number of region sizes reported to the master."),
+			"numSpaceSnapshotsReceived":    newMetric(prometheus.GaugeValue, "space_snapshots_received", "The number of space quota snapshots received from the master."),
+			"numRegionSizeReportsSent":     newMetric(prometheus.CounterValue, "region_size_reports_sent", "The number of region size reports sent to the master."),
+		},
+		choreTime: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "chore_time_ms"),
+			"The time of the quota chores in milliseconds, by chore.",
+			append(defaultHBaseQuotaLabels, "chore"), nil,
+		),
+		tableSpaceUsage: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "table_space_usage_bytes"),
+			"The space used by a table with a space quota in bytes.",
+			append(defaultHBaseQuotaLabels, "namespace", "htable"), nil,
+		),
+		tableSpaceLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "table_space_limit_bytes"),
+			"The space quota of a table in bytes.",
+			append(defaultHBaseQuotaLabels, "namespace", "htable"), nil,
+		),
+		namespaceSpaceUsage: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "namespace_space_usage_bytes"),
+			"The space used by a namespace with a space quota in bytes.",
+			append(defaultHBaseQuotaLabels, "namespace"), nil,
+		),
+		namespaceSpaceLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "namespace_space_limit_bytes"),
+			"The space quota of a namespace in bytes.",
+			append(defaultHBaseQuotaLabels, "namespace"), nil,
+		),
+	}
+}
+
+func (m *HBaseQuota) upGauge() prometheus.Gauge {
+	return m.up
+}
+
+func (m *HBaseQuota) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range m.metrics {
+		ch <- metric.Desc
+	}
+	ch <- m.choreTime
+	ch <- m.tableSpaceUsage
+	ch <- m.tableSpaceLimit
+	ch <- m.namespaceSpaceUsage
+	ch <- m.namespaceSpaceLimit
+
+	ch <- m.up.Desc()
+	ch <- m.totalScrapes.Desc()
+	ch <- m.jsonParseFailures.Desc()
+}
+
+func (m *HBaseQuota) Collect(ch chan<- prometheus.Metric) {
+	m.totalScrapes.Inc()
+	defer func() {
+		ch <- m.up
+		ch <- m.totalScrapes
+		ch <- m.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name="+m.service+",sub=*Quota*")
+	if err != nil {
+		if isJmxReadError(err) {
+			m.jsonParseFailures.Inc()
+		}
+		m.up.Set(0)
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to fetch quota metrics",
+			"err", err,
+		)
+		return
+	}
+
+	beans, err := allBeans(bts)
+	if err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode quota metrics",
+			"err", err,
+		)
+		return
+	}
+	m.up.Set(1)
+
+	for _, bean := range beans {
+		var hbaseQuotaResp hbaseQuotaResponse
+		if err := json.Unmarshal([]byte(bean.Raw), &hbaseQuotaResp); err != nil {
+			m.jsonParseFailures.Inc()
+			_ = level.Warn(m.logger).Log(
+				"msg", "failed to decode quota metrics",
+				"bean", bean.Get("name").String(),
+				"err", err,
+			)
+			continue
+		}
+
+		labels := []string{hbaseQuotaResp.Host, strings.ToLower(m.service)}
+		attrs := bean.Map()
+
+		for attr, metric := range m.metrics {
+			if v, ok := attrs[attr]; ok {
+				ch <- prometheus.MustNewConstMetric(
+					metric.Desc,
+					metric.Type,
+					v.Float(),
+					labels...,
+				)
+			}
+		}
+
+		for attr, chore := range hbaseQuotaChores {
+			count, sum, quantiles, ok := histogramSummary(attrs, attr)
+			if !ok {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstSummary(
+				m.choreTime,
+				count,
+				sum,
+				quantiles,
+				append(labels, chore)...,
+			)
+		}
+
+		for _, quota := range parseSpaceQuotas(hbaseQuotaResp.TableSpaceQuotaSnapshots) {
+			ns, table := "default", quota.Name
+			if i := strings.Index(quota.Name, ":"); i >= 0 {
+				ns, table = quota.Name[:i], quota.Name[i+1:]
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				m.tableSpaceUsage,
+				prometheus.GaugeValue,
+				quota.Usage,
+				append(labels, ns, table)...,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				m.tableSpaceLimit,
+				prometheus.GaugeValue,
+				quota.Limit,
+				append(labels, ns, table)...,
+			)
+		}
+
+		for _, quota := range parseSpaceQuotas(hbaseQuotaResp.NamespaceSpaceQuotaSnapshots) {
+			ch <- prometheus.MustNewConstMetric(
+				m.namespaceSpaceUsage,
+				prometheus.GaugeValue,
+				quota.Usage,
+				append(labels, quota.Name)...,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				m.namespaceSpaceLimit,
+				prometheus.GaugeValue,
+				quota.Limit,
+				append(labels, quota.Name)...,
+			)
+		}
+	}
+}
+
+// parseSpaceQuotas parses the space quota snapshots of the master quota bean.
+// They are not JSON but java map strings such as
+// [{table=ns:t1, usage=1024, limit=4096}, {table=t2, usage=0, limit=1024}],
+// keyed by namespace instead of table for namespace quotas. Entries without
+// a name or with an unparsable usage or limit are skipped.
+func parseSpaceQuotas(snapshots string) []hbaseSpaceQuota {
+	snapshots = strings.TrimSpace(snapshots)
+	snapshots = strings.TrimPrefix(snapshots, "[")
+	snapshots = strings.TrimSuffix(snapshots, "]")
+
+	var quotas []hbaseSpaceQuota
+	for _, entry := range strings.Split(snapshots, "}") {
+		entry = strings.TrimLeft(entry, ", {")
+		if entry == "" {
+			continue
+		}
+
+		var quota hbaseSpaceQuota
+		var usage, limit bool
+		for _, kv := range strings.Split(entry, ",") {
+			parts := strings.SplitN(strings.TrimSpace(kv), "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			var err error
+			switch parts[0] {
+			case "table", "namespace":
+				quota.Name = parts[1]
+			case "usage":
+				quota.Usage, err = strconv.ParseFloat(parts[1], 64)
+				usage = err == nil
+			case "limit":
+				quota.Limit, err = strconv.ParseFloat(parts[1], 64)
+				limit = err == nil
+			}
+		}
+		if quota.Name != "" && usage && limit {
+			quotas = append(quotas, quota)
+		}
+	}
+
+	return quotas
+}
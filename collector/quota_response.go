@@ -0,0 +1,7 @@
+package collector
+
+type hbaseQuotaResponse struct {
+	Host                         string `json:"tag.Hostname"`
+	TableSpaceQuotaSnapshots     string `json:"tag.tableSpaceQuotaSnapshots"`
+	NamespaceSpaceQuotaSnapshots string `json:"tag.namespaceSpaceQuotaSnapshots"`
+}
@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestParseSpaceQuotas(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		snapshots string
+		want      []hbaseSpaceQuota
+	}{
+		{
+			name:      "table",
+			snapshots: "[{table=ns:t1, usage=1024, limit=4096}]",
+			want:      []hbaseSpaceQuota{{Name: "ns:t1", Usage: 1024, Limit: 4096}},
+		},
+		{
+			name:      "several",
+			snapshots: "[{table=ns:t1, usage=1024, limit=4096}, {table=t2, usage=0, limit=1024}]",
+			want: []hbaseSpaceQuota{
+				{Name: "ns:t1", Usage: 1024, Limit: 4096},
+				{Name: "t2", Usage: 0, Limit: 1024},
+			},
+		},
+		{
+			name:      "namespace",
+			snapshots: "[{namespace=ns1, usage=2048, limit=8192}]",
+			want:      []hbaseSpaceQuota{{Name: "ns1", Usage: 2048, Limit: 8192}},
+		},
+		{
+			name:      "empty",
+			snapshots: "[]",
+		},
+		{
+			name:      "missing tag",
+			snapshots: "",
+		},
+		{
+			name:      "malformed entries",
+			snapshots: "[{table=t1, usage=abc, limit=4096}, {usage=1, limit=2}, {table=t3, usage=1}, {table=t4 usage=1 limit=2}, {table=t5, usage=5, limit=6}]",
+			want:      []hbaseSpaceQuota{{Name: "t5", Usage: 5, Limit: 6}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := parseSpaceQuotas(test.snapshots)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHBaseQuota(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		service string
+		fixture string
+		want    map[string]float64
+		absent  []string
+	}{
+		{
+			name:    "master",
+			service: MasterService,
+			fixture: "master_quotas.json",
+			want: map[string]float64{
+				`hbase_quota_up{}`: 1,
+				`hbase_quota_space_quotas{host="master1.example.com",role="master"}`:                                            3,
+				`hbase_quota_tables_in_violation{host="master1.example.com",role="master"}`:                                     1,
+				`hbase_quota_namespaces_in_violation{host="master1.example.com",role="master"}`:                                 0,
+				`hbase_quota_region_size_reports{host="master1.example.com",role="master"}`:                                     12,
+				`hbase_quota_chore_time_ms_count{chore="quota_observer",host="master1.example.com",role="master"}`:              40,
+				`hbase_quota_chore_time_ms_sum{chore="quota_observer",host="master1.example.com",role="master"}`:                260,
+				`hbase_quota_chore_time_ms_count{chore="snapshot_observer",host="master1.example.com",role="master"}`:           0,
+				`hbase_quota_table_space_usage_bytes{host="master1.example.com",htable="t1",namespace="ns1",role="master"}`:     1024,
+				`hbase_quota_table_space_limit_bytes{host="master1.example.com",htable="t1",namespace="ns1",role="master"}`:     4096,
+				`hbase_quota_table_space_usage_bytes{host="master1.example.com",htable="t2",namespace="default",role="master"}`: 0,
+				`hbase_quota_table_space_limit_bytes{host="master1.example.com",htable="t2",namespace="default",role="master"}`: 1048576,
+				`hbase_quota_namespace_space_usage_bytes{host="master1.example.com",namespace="ns1",role="master"}`:             2048,
+				`hbase_quota_namespace_space_limit_bytes{host="master1.example.com",namespace="ns1",role="master"}`:             8192,
+			},
+			absent: []string{"hbase_quota_region_size_reports_sent"},
+		},
+		{
+			name:    "regionserver",
+			service: RegionServerService,
+			fixture: "regionserver_quotas.json",
+			want: map[string]float64{
+				`hbase_quota_up{}`: 1,
+				`hbase_quota_space_snapshots_received{host="rs1.example.com",role="regionserver"}`:                            2,
+				`hbase_quota_region_size_reports_sent{host="rs1.example.com",role="regionserver"}`:                            57,
+				`hbase_quota_chore_time_ms_count{chore="file_system_utilization",host="rs1.example.com",role="regionserver"}`: 10,
+				`hbase_quota_chore_time_ms_sum{chore="file_system_utilization",host="rs1.example.com",role="regionserver"}`:   40,
+			},
+			absent: []string{"hbase_quota_table_space", "hbase_quota_namespace_space"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			jmx := newJmxFixtureClient(t, map[string]string{
+				"Hadoop:service=HBase,name=" + test.service + ",sub=*Quota*": test.fixture,
+			})
+
+			got := gatherValues(t, NewHBaseQuota(log.NewNopLogger(), jmx, test.service))
+			checkValues(t, got, test.want, test.absent...)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseRestServerLabels      = []string{"host", "role"}
+	defaultHBaseRestServerLabelValues = func(restServer restServerResponse) []string {
+		return []string{
+			restServer.Host,
+			strings.ToLower(restServer.Role),
+		}
+	}
+
+	// hbaseRestOperations maps the suffix of the successful<Op> and
+	// failed<Op> attributes to the operation label.
+	hbaseRestOperations = map[string]string{
+		"Get":            "get",
+		"Put":            "put",
+		"Delete":         "delete",
+		"ScanCount":      "scan",
+		"AppendCount":    "append",
+		"IncrementCount": "increment",
+	}
+)
+
+// RestServer collects the metrics of an HBase REST gateway.
+type RestServer struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	requests   *prometheus.Desc
+	successful *prometheus.Desc
+	failed     *prometheus.Desc
+}
+
+func NewRestServer(logger log.Logger, jmx *JmxClient) *RestServer {
+	subsystem := "rest"
+
+	return &RestServer{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase rest endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase rest scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		requests: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "requests"),
+			"The number of requests received by the rest gateway.",
+			defaultHBaseRestServerLabels, nil,
+		),
+		successful: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "successful_requests"),
+			"The number of successful rest requests, by operation.",
+			append(defaultHBaseRestServerLabels, "operation"), nil,
+		),
+		failed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "failed_requests"),
+			"The number of failed rest requests, by operation.",
+			append(defaultHBaseRestServerLabels, "operation"), nil,
+		),
+	}
+}
+
+func (m *RestServer) upGauge() prometheus.Gauge {
+	return m.up
+}
+
+func (m *RestServer) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.requests
+	ch <- m.successful
+	ch <- m.failed
+
+	ch <- m.up.Desc()
+	ch <- m.totalScrapes.Desc()
+	ch <- m.jsonParseFailures.Desc()
+}
+
+func (m *RestServer) Collect(ch chan<- prometheus.Metric) {
+	m.totalScrapes.Inc()
+	defer func() {
+		ch <- m.up
+		ch <- m.totalScrapes
+		ch <- m.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name=REST")
+	if err != nil {
+		if isJmxReadError(err) {
+			m.jsonParseFailures.Inc()
+		}
+		m.up.Set(0)
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to fetch rest metrics",
+			"err", err,
+		)
+		return
+	}
+
+	bean, err := firstBean(bts)
+	if err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode rest metrics",
+			"err", err,
+		)
+		return
+	}
+
+	var restServerResp restServerResponse
+	if err := json.Unmarshal([]byte(bean.Raw), &restServerResp); err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode rest metrics",
+			"err", err,
+		)
+		return
+	}
+	m.up.Set(1)
+
+	labels := defaultHBaseRestServerLabelValues(restServerResp)
+
+	ch <- prometheus.MustNewConstMetric(
+		m.requests,
+		prometheus.CounterValue,
+		restServerResp.Requests,
+		labels...,
+	)
+
+	attrs := bean.Map()
+	for attr, operation := range hbaseRestOperations {
+		if v, ok := attrs["successful"+attr]; ok {
+			ch <- prometheus.MustNewConstMetric(
+				m.successful,
+				prometheus.CounterValue,
+				v.Float(),
+				append(labels, operation)...,
+			)
+		}
+		if v, ok := attrs["failed"+attr]; ok {
+			ch <- prometheus.MustNewConstMetric(
+				m.failed,
+				prometheus.CounterValue,
+				v.Float(),
+				append(labels, operation)...,
+			)
+		}
+	}
+}
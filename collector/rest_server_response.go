@@ -0,0 +1,7 @@
+package collector
+
+type restServerResponse struct {
+	Host     string  `json:"tag.Hostname"`
+	Role     string  `json:"tag.Context"`
+	Requests float64 `json:"requests"`
+}
@@ -0,0 +1,31 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestRestServer(t *testing.T) {
+	jmx := newJmxFixtureClient(t, map[string]string{
+		"Hadoop:service=HBase,name=REST": "rest.json",
+	})
+
+	got := gatherValues(t, NewRestServer(log.NewNopLogger(), jmx))
+	checkValues(t, got, map[string]float64{
+		`hbase_rest_up{}`: 1,
+		`hbase_rest_requests{host="rest1.example.com",role="rest"}`:                                  412,
+		`hbase_rest_successful_requests{host="rest1.example.com",operation="get",role="rest"}`:       180,
+		`hbase_rest_successful_requests{host="rest1.example.com",operation="put",role="rest"}`:       97,
+		`hbase_rest_successful_requests{host="rest1.example.com",operation="delete",role="rest"}`:    12,
+		`hbase_rest_successful_requests{host="rest1.example.com",operation="scan",role="rest"}`:      64,
+		`hbase_rest_successful_requests{host="rest1.example.com",operation="append",role="rest"}`:    9,
+		`hbase_rest_successful_requests{host="rest1.example.com",operation="increment",role="rest"}`: 31,
+		`hbase_rest_failed_requests{host="rest1.example.com",operation="get",role="rest"}`:           3,
+		`hbase_rest_failed_requests{host="rest1.example.com",operation="put",role="rest"}`:           1,
+		`hbase_rest_failed_requests{host="rest1.example.com",operation="delete",role="rest"}`:        0,
+		`hbase_rest_failed_requests{host="rest1.example.com",operation="scan",role="rest"}`:          2,
+		`hbase_rest_failed_requests{host="rest1.example.com",operation="append",role="rest"}`:        1,
+		`hbase_rest_failed_requests{host="rest1.example.com",operation="increment",role="rest"}`:     4,
+	})
+}
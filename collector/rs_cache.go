@@ -0,0 +1,365 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	defaultHBaseRsCacheLabels      = []string{"host", "role"}
+	defaultHBaseRsCacheLabelValues = func(rsCache rsCacheResponse) []string {
+		return []string{
+			rsCache.Host,
+			strings.ToLower(rsCache.Role),
+		}
+	}
+
+	defaultHBaseRsCacheReplicaLabels = []string{"host", "role", "replica"}
+	hbaseRsCachePrimaryLabelValues   = func(rsCache rsCacheResponse) []string {
+		return append(defaultHBaseRsCacheLabelValues(rsCache), "primary")
+	}
+	hbaseRsCacheSecondaryLabelValues = func(rsCache rsCacheResponse) []string {
+		return append(defaultHBaseRsCacheLabelValues(rsCache), "secondary")
+	}
+
+	// hbaseBlockTypes maps the block types HBase counts hits and misses
+	// for, e.g. blockCacheDataHitCount, to their block_type label.
+	hbaseBlockTypes = map[string]string{
+		"Data":              "data",
+		"Meta":              "meta",
+		"FileInfo":          "file_info",
+		"Trailer":           "trailer",
+		"LeafIndex":         "leaf_index",
+		"RootIndex":         "root_index",
+		"IntermediateIndex": "intermediate_index",
+		"BloomChunk":        "bloom_chunk",
+		"GeneralBloomMeta":  "general_bloom_meta",
+		"DeleteFamilyBloom": "delete_family_bloom",
+	}
+)
+
+type rsCacheMetric struct {
+	Type   prometheus.ValueType
+	Desc   *prometheus.Desc
+	Value  func(rsCache rsCacheResponse) float64
+	Labels func(rsCache rsCacheResponse) []string
+}
+
+// RsCache collects the block cache metrics of a regionserver, including
+// the bucket cache when one is configured.
+type RsCache struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	metrics                    []*rsCacheMetric
+	blockTypeHits              *prometheus.Desc
+	blockTypeMisses            *prometheus.Desc
+	bucketCacheIoHitsPerSecond *prometheus.Desc
+	bucketCacheHistograms      []*hbaseHistogram
+}
+
+func NewRsCache(logger log.Logger, jmx *JmxClient) *RsCache {
+	subsystem := "blockcache"
+
+	hitCount := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "hit_count"),
+		"The number of block cache hits, by region replica.",
+		defaultHBaseRsCacheReplicaLabels, nil,
+	)
+	missCount := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "miss_count"),
+		"The number of block cache misses, by region replica.",
+		defaultHBaseRsCacheReplicaLabels, nil,
+	)
+	evictionCount := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "eviction_count"),
+		"The number of block cache evictions, by region replica.",
+		defaultHBaseRsCacheReplicaLabels, nil,
+	)
+
+	return &RsCache{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase block cache endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase block cache scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		metrics: []*rsCacheMetric{
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "size_bytes"),
+					"The size of the block cache in bytes.",
+					defaultHBaseRsCacheLabels, nil,
+				),
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheSize
+				},
+				Labels: defaultHBaseRsCacheLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "free_size_bytes"),
+					"The free size of the block cache in bytes.",
+					defaultHBaseRsCacheLabels, nil,
+				),
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheFreeSize
+				},
+				Labels: defaultHBaseRsCacheLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "count"),
+					"The number of blocks in the block cache.",
+					defaultHBaseRsCacheLabels, nil,
+				),
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheCount
+				},
+				Labels: defaultHBaseRsCacheLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "count_hit_percent"),
+					"The percentage of block cache requests that were hits.",
+					defaultHBaseRsCacheLabels, nil,
+				),
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheCountHitPercent
+				},
+				Labels: defaultHBaseRsCacheLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "express_hit_percent"),
+					"The percentage of block cache requests with caching turned on that were hits.",
+					defaultHBaseRsCacheLabels, nil,
+				),
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheExpressHitPercent
+				},
+				Labels: defaultHBaseRsCacheLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "failed_insertion_count"),
+					"The number of blocks that could not be inserted into the block cache.",
+					defaultHBaseRsCacheLabels, nil,
+				),
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheFailedInsertionCount
+				},
+				Labels: defaultHBaseRsCacheLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: hitCount,
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheHitCountPrimary
+				},
+				Labels: hbaseRsCachePrimaryLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: hitCount,
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheHitCount - rsCache.BlockCacheHitCountPrimary
+				},
+				Labels: hbaseRsCacheSecondaryLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: missCount,
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheMissCountPrimary
+				},
+				Labels: hbaseRsCachePrimaryLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: missCount,
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheMissCount - rsCache.BlockCacheMissCountPrimary
+				},
+				Labels: hbaseRsCacheSecondaryLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: evictionCount,
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheEvictionCountPrimary
+				},
+				Labels: hbaseRsCachePrimaryLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: evictionCount,
+				Value: func(rsCache rsCacheResponse) float64 {
+					return rsCache.BlockCacheEvictionCount - rsCache.BlockCacheEvictionCountPrimary
+				},
+				Labels: hbaseRsCacheSecondaryLabelValues,
+			},
+		},
+
+		blockTypeHits: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "block_type_hit_count"),
+			"The number of block cache hits, by block type.",
+			append(defaultHBaseRsCacheLabels, "block_type"), nil,
+		),
+		blockTypeMisses: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "block_type_miss_count"),
+			"The number of block cache misses, by block type.",
+			append(defaultHBaseRsCacheLabels, "block_type"), nil,
+		),
+
+		bucketCacheIoHitsPerSecond: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bucketcache", "io_hits_per_second"),
+			"The number of bucket cache reads from the IOEngine per second.",
+			defaultHBaseRsCacheLabels, nil,
+		),
+		bucketCacheHistograms: []*hbaseHistogram{
+			{
+				Attr: "IOTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "bucketcache", "io_time_ms"),
+					"The time spent reading from the bucket cache IOEngine in milliseconds.",
+					defaultHBaseRsCacheLabels, nil,
+				),
+			},
+		},
+	}
+}
+
+func (r *RsCache) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range r.metrics {
+		ch <- metric.Desc
+	}
+	ch <- r.blockTypeHits
+	ch <- r.blockTypeMisses
+	ch <- r.bucketCacheIoHitsPerSecond
+	for _, histogram := range r.bucketCacheHistograms {
+		ch <- histogram.Desc
+	}
+
+	ch <- r.up.Desc()
+	ch <- r.totalScrapes.Desc()
+	ch <- r.jsonParseFailures.Desc()
+}
+
+// collectServerBean exports the block cache metrics of the sub=Server bean
+// fetched by RsServer, or only up 0 if fetching it failed.
+func (r *RsCache) collectServerBean(ch chan<- prometheus.Metric, bean gjson.Result, err error) {
+	r.totalScrapes.Inc()
+	defer func() {
+		ch <- r.up
+		ch <- r.totalScrapes
+		ch <- r.jsonParseFailures
+	}()
+
+	if err != nil {
+		r.up.Set(0)
+		return
+	}
+
+	var rsCacheResp rsCacheResponse
+	if err := json.Unmarshal([]byte(bean.Raw), &rsCacheResp); err != nil {
+		r.up.Set(0)
+		r.jsonParseFailures.Inc()
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to decode block cache metrics",
+			"err", err,
+		)
+		return
+	}
+	r.up.Set(1)
+
+	for _, metric := range r.metrics {
+		ch <- prometheus.MustNewConstMetric(
+			metric.Desc,
+			metric.Type,
+			metric.Value(rsCacheResp),
+			metric.Labels(rsCacheResp)...,
+		)
+	}
+
+	labels := defaultHBaseRsCacheLabelValues(rsCacheResp)
+	data := bean.Map()
+
+	for blockType, label := range hbaseBlockTypes {
+		if v, ok := data["blockCache"+blockType+"HitCount"]; ok {
+			ch <- prometheus.MustNewConstMetric(
+				r.blockTypeHits,
+				prometheus.CounterValue,
+				v.Float(),
+				append(labels, label)...,
+			)
+		}
+		if v, ok := data["blockCache"+blockType+"MissCount"]; ok {
+			ch <- prometheus.MustNewConstMetric(
+				r.blockTypeMisses,
+				prometheus.CounterValue,
+				v.Float(),
+				append(labels, label)...,
+			)
+		}
+	}
+
+	r.collectBucketCache(ch, labels)
+}
+
+// collectBucketCache exports the bucket cache bean, which only exists when
+// hbase.bucketcache.ioengine is set, so its absence is not an error.
+func (r *RsCache) collectBucketCache(ch chan<- prometheus.Metric, labels []string) {
+	bts, err := fetchJmx(r.logger, r.jmx, "Hadoop:service=HBase,name=RegionServer,sub=BucketCache")
+	if err != nil {
+		if isJmxReadError(err) {
+			r.jsonParseFailures.Inc()
+		}
+		_ = level.Debug(r.logger).Log(
+			"msg", "failed to fetch bucket cache metrics",
+			"err", err,
+		)
+		return
+	}
+
+	beans := gjson.GetBytes(bts, "beans").Array()
+	if len(beans) == 0 {
+		return
+	}
+	data := beans[0].Map()
+
+	if v, ok := data["ioHitsPerSecond"]; ok {
+		ch <- prometheus.MustNewConstMetric(
+			r.bucketCacheIoHitsPerSecond,
+			prometheus.GaugeValue,
+			v.Float(),
+			labels...,
+		)
+	}
+
+	collectHistograms(ch, r.bucketCacheHistograms, data, labels...)
+}
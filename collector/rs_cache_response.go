@@ -0,0 +1,18 @@
+package collector
+
+type rsCacheResponse struct {
+	Host                           string  `json:"tag.Hostname"`
+	Role                           string  `json:"tag.Context"`
+	BlockCacheSize                 float64 `json:"blockCacheSize"`
+	BlockCacheFreeSize             float64 `json:"blockCacheFreeSize"`
+	BlockCacheCount                float64 `json:"blockCacheCount"`
+	BlockCacheCountHitPercent      float64 `json:"blockCacheCountHitPercent"`
+	BlockCacheExpressHitPercent    float64 `json:"blockCacheExpressHitPercent"`
+	BlockCacheFailedInsertionCount float64 `json:"blockCacheFailedInsertionCount"`
+	BlockCacheHitCount             float64 `json:"blockCacheHitCount"`
+	BlockCacheHitCountPrimary      float64 `json:"blockCacheHitCountPrimary"`
+	BlockCacheMissCount            float64 `json:"blockCacheMissCount"`
+	BlockCacheMissCountPrimary     float64 `json:"blockCacheMissCountPrimary"`
+	BlockCacheEvictionCount        float64 `json:"blockCacheEvictionCount"`
+	BlockCacheEvictionCountPrimary float64 `json:"blockCacheEvictionCountPrimary"`
+}
@@ -0,0 +1,291 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	defaultHBaseRsCompactionLabels      = []string{"host", "role"}
+	defaultHBaseRsCompactionLabelValues = func(rsCompaction rsCompactionResponse) []string {
+		return []string{
+			rsCompaction.Host,
+			strings.ToLower(rsCompaction.Role),
+		}
+	}
+
+	defaultHBaseRsCompactionPoolLabels = []string{"host", "role", "pool"}
+)
+
+type rsCompactionMetric struct {
+	Type   prometheus.ValueType
+	Desc   *prometheus.Desc
+	Value  func(rsCompaction rsCompactionResponse) float64
+	Labels func(rsCompaction rsCompactionResponse) []string
+}
+
+// RsCompaction collects the server-level compaction metrics of a
+// regionserver. The per-region compaction metrics are part of RsRegion.
+type RsCompaction struct {
+	logger log.Logger
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	metrics    []*rsCompactionMetric
+	histograms []*hbaseHistogram
+}
+
+func NewRsCompaction(logger log.Logger) *RsCompaction {
+	subsystem := "compaction"
+
+	queueLength := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "queue_length"),
+		"The number of compactions queued, by thread pool.",
+		defaultHBaseRsCompactionPoolLabels, nil,
+	)
+
+	return &RsCompaction{
+		logger: logger,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase compaction endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase compaction scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		metrics: []*rsCompactionMetric{
+			{
+				Type: prometheus.GaugeValue,
+				Desc: queueLength,
+				Value: func(rsCompaction rsCompactionResponse) float64 {
+					return rsCompaction.SmallCompactionQueueLength
+				},
+				Labels: func(rsCompaction rsCompactionResponse) []string {
+					return append(defaultHBaseRsCompactionLabelValues(rsCompaction), "small")
+				},
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: queueLength,
+				Value: func(rsCompaction rsCompactionResponse) float64 {
+					return rsCompaction.LargeCompactionQueueLength
+				},
+				Labels: func(rsCompaction rsCompactionResponse) []string {
+					return append(defaultHBaseRsCompactionLabelValues(rsCompaction), "large")
+				},
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "compacted_cells_count"),
+					"The number of cells processed by compactions.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+				Value: func(rsCompaction rsCompactionResponse) float64 {
+					return rsCompaction.CompactedCellsCount
+				},
+				Labels: defaultHBaseRsCompactionLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "compacted_cells_size_bytes"),
+					"The size of the cells processed by compactions in bytes.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+				Value: func(rsCompaction rsCompactionResponse) float64 {
+					return rsCompaction.CompactedCellsSize
+				},
+				Labels: defaultHBaseRsCompactionLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "major_compacted_cells_count"),
+					"The number of cells processed by major compactions.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+				Value: func(rsCompaction rsCompactionResponse) float64 {
+					return rsCompaction.MajorCompactedCellsCount
+				},
+				Labels: defaultHBaseRsCompactionLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "major_compacted_cells_size_bytes"),
+					"The size of the cells processed by major compactions in bytes.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+				Value: func(rsCompaction rsCompactionResponse) float64 {
+					return rsCompaction.MajorCompactedCellsSize
+				},
+				Labels: defaultHBaseRsCompactionLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "compacted_input_bytes"),
+					"The number of bytes read by compactions.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+				Value: func(rsCompaction rsCompactionResponse) float64 {
+					return rsCompaction.CompactedInputBytes
+				},
+				Labels: defaultHBaseRsCompactionLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "compacted_output_bytes"),
+					"The number of bytes written by compactions.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+				Value: func(rsCompaction rsCompactionResponse) float64 {
+					return rsCompaction.CompactedOutputBytes
+				},
+				Labels: defaultHBaseRsCompactionLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "major_compacted_input_bytes"),
+					"The number of bytes read by major compactions.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+				Value: func(rsCompaction rsCompactionResponse) float64 {
+					return rsCompaction.MajorCompactedInputBytes
+				},
+				Labels: defaultHBaseRsCompactionLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "major_compacted_output_bytes"),
+					"The number of bytes written by major compactions.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+				Value: func(rsCompaction rsCompactionResponse) float64 {
+					return rsCompaction.MajorCompactedOutputBytes
+				},
+				Labels: defaultHBaseRsCompactionLabelValues,
+			},
+		},
+
+		histograms: []*hbaseHistogram{
+			{
+				Attr: "CompactionTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "time_ms"),
+					"The time of compactions in milliseconds.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+			},
+			{
+				Attr: "MajorCompactionTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "major_time_ms"),
+					"The time of major compactions in milliseconds.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+			},
+			{
+				Attr: "CompactionInputFileCount",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "input_file_count"),
+					"The number of files read by compactions.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+			},
+			{
+				Attr: "CompactionInputSize",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "input_size_bytes"),
+					"The size of the files read by compactions in bytes.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+			},
+			{
+				Attr: "CompactionOutputFileCount",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "output_file_count"),
+					"The number of files written by compactions.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+			},
+			{
+				Attr: "CompactionOutputSize",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "output_size_bytes"),
+					"The size of the files written by compactions in bytes.",
+					defaultHBaseRsCompactionLabels, nil,
+				),
+			},
+		},
+	}
+}
+
+func (r *RsCompaction) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range r.metrics {
+		ch <- metric.Desc
+	}
+	for _, histogram := range r.histograms {
+		ch <- histogram.Desc
+	}
+
+	ch <- r.up.Desc()
+	ch <- r.totalScrapes.Desc()
+	ch <- r.jsonParseFailures.Desc()
+}
+
+// collectServerBean exports the compaction metrics of the sub=Server bean
+// fetched by RsServer, or only up 0 if fetching it failed.
+func (r *RsCompaction) collectServerBean(ch chan<- prometheus.Metric, bean gjson.Result, err error) {
+	r.totalScrapes.Inc()
+	defer func() {
+		ch <- r.up
+		ch <- r.totalScrapes
+		ch <- r.jsonParseFailures
+	}()
+
+	if err != nil {
+		r.up.Set(0)
+		return
+	}
+
+	var rsCompactionResp rsCompactionResponse
+	if err := json.Unmarshal([]byte(bean.Raw), &rsCompactionResp); err != nil {
+		r.up.Set(0)
+		r.jsonParseFailures.Inc()
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to decode compaction metrics",
+			"err", err,
+		)
+		return
+	}
+	r.up.Set(1)
+
+	for _, metric := range r.metrics {
+		ch <- prometheus.MustNewConstMetric(
+			metric.Desc,
+			metric.Type,
+			metric.Value(rsCompactionResp),
+			metric.Labels(rsCompactionResp)...,
+		)
+	}
+
+	collectHistograms(ch, r.histograms, bean.Map(), defaultHBaseRsCompactionLabelValues(rsCompactionResp)...)
+}
@@ -0,0 +1,16 @@
+package collector
+
+type rsCompactionResponse struct {
+	Host                       string  `json:"tag.Hostname"`
+	Role                       string  `json:"tag.Context"`
+	SmallCompactionQueueLength float64 `json:"smallCompactionQueueLength"`
+	LargeCompactionQueueLength float64 `json:"largeCompactionQueueLength"`
+	CompactedCellsCount        float64 `json:"compactedCellsCount"`
+	CompactedCellsSize         float64 `json:"compactedCellsSize"`
+	MajorCompactedCellsCount   float64 `json:"majorCompactedCellsCount"`
+	MajorCompactedCellsSize    float64 `json:"majorCompactedCellsSize"`
+	CompactedInputBytes        float64 `json:"compactedInputBytes"`
+	CompactedOutputBytes       float64 `json:"compactedOutputBytes"`
+	MajorCompactedInputBytes   float64 `json:"majorCompactedInputBytes"`
+	MajorCompactedOutputBytes  float64 `json:"majorCompactedOutputBytes"`
+}
@@ -0,0 +1,56 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestRsCompaction(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		fixture string
+		want    map[string]float64
+		absent  []string
+	}{
+		{
+			name:    "server bean",
+			fixture: "regionserver_server.json",
+			want: map[string]float64{
+				`hbase_compaction_up{}`: 1,
+				`hbase_compaction_queue_length{host="rs1.example.com",pool="small",role="regionserver"}`:        2,
+				`hbase_compaction_queue_length{host="rs1.example.com",pool="large",role="regionserver"}`:        1,
+				`hbase_compaction_compacted_cells_count{host="rs1.example.com",role="regionserver"}`:            120000,
+				`hbase_compaction_major_compacted_cells_size_bytes{host="rs1.example.com",role="regionserver"}`: 36000000,
+				`hbase_compaction_compacted_input_bytes{host="rs1.example.com",role="regionserver"}`:            64000000,
+				`hbase_compaction_major_compacted_output_bytes{host="rs1.example.com",role="regionserver"}`:     32000000,
+				`hbase_compaction_time_ms_count{host="rs1.example.com",role="regionserver"}`:                    40,
+				`hbase_compaction_time_ms_sum{host="rs1.example.com",role="regionserver"}`:                      60000,
+				`hbase_compaction_major_time_ms_count{host="rs1.example.com",role="regionserver"}`:              4,
+				`hbase_compaction_input_file_count_count{host="rs1.example.com",role="regionserver"}`:           40,
+				`hbase_compaction_output_size_bytes_sum{host="rs1.example.com",role="regionserver"}`:            50000000,
+			},
+			// Histograms the bean does not publish are left out.
+			absent: []string{"hbase_compaction_output_file_count"},
+		},
+		{
+			name: "no server bean",
+			want: map[string]float64{
+				`hbase_compaction_up{}`: 0,
+			},
+			absent: []string{"hbase_compaction_queue_length", "hbase_compaction_time_ms"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fixtures := map[string]string{}
+			if test.fixture != "" {
+				fixtures["Hadoop:service=HBase,name=RegionServer,sub=Server"] = test.fixture
+			}
+			jmx := newJmxFixtureClient(t, fixtures)
+			logger := log.NewNopLogger()
+
+			got := gatherValues(t, NewRsServer(logger, jmx, NewRsCompaction(logger)))
+			checkValues(t, got, test.want, test.absent...)
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	defaultHBaseRsHdfsLabels      = []string{"host", "role"}
+	defaultHBaseRsHdfsLabelValues = func(rsHdfs rsHdfsResponse) []string {
+		return []string{
+			rsHdfs.Host,
+			strings.ToLower(rsHdfs.Role),
+		}
+	}
+)
+
+type rsHdfsMetric struct {
+	Type   prometheus.ValueType
+	Desc   *prometheus.Desc
+	Value  func(rsHdfs rsHdfsResponse) float64
+	Labels func(rsHdfs rsHdfsResponse) []string
+}
+
+// RsHdfs collects the HDFS client metrics of a regionserver: the hedged and
+// short-circuit reads of the Server bean and the filesystem latencies of the
+// IO bean.
+type RsHdfs struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	serverMetrics []*rsHdfsMetric
+	ioMetrics     []*rsHdfsMetric
+	histograms    []*hbaseHistogram
+}
+
+func NewRsHdfs(logger log.Logger, jmx *JmxClient) *RsHdfs {
+	subsystem := "hdfs"
+
+	return &RsHdfs{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase hdfs endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase hdfs scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		serverMetrics: []*rsHdfsMetric{
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "hedged_reads"),
+					"The number of hedged reads started because a datanode was slow.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+				Value: func(rsHdfs rsHdfsResponse) float64 {
+					return rsHdfs.HedgedReads
+				},
+				Labels: defaultHBaseRsHdfsLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "hedged_read_wins"),
+					"The number of hedged reads that returned before the original read.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+				Value: func(rsHdfs rsHdfsResponse) float64 {
+					return rsHdfs.HedgedReadWins
+				},
+				Labels: defaultHBaseRsHdfsLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "hedged_read_ops_in_cur_thread"),
+					"The number of hedged reads run in the calling thread because the hedged read pool was full.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+				Value: func(rsHdfs rsHdfsResponse) float64 {
+					return rsHdfs.HedgedReadOpsInCurThread
+				},
+				Labels: defaultHBaseRsHdfsLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "total_bytes_read"),
+					"The number of bytes read from HDFS.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+				Value: func(rsHdfs rsHdfsResponse) float64 {
+					return rsHdfs.TotalBytesRead
+				},
+				Labels: defaultHBaseRsHdfsLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "local_bytes_read"),
+					"The number of bytes read from a local datanode.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+				Value: func(rsHdfs rsHdfsResponse) float64 {
+					return rsHdfs.LocalBytesRead
+				},
+				Labels: defaultHBaseRsHdfsLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "short_circuit_bytes_read"),
+					"The number of bytes read through short-circuit local reads.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+				Value: func(rsHdfs rsHdfsResponse) float64 {
+					return rsHdfs.ShortCircuitBytesRead
+				},
+				Labels: defaultHBaseRsHdfsLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "zero_copy_bytes_read"),
+					"The number of bytes read through zero-copy reads.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+				Value: func(rsHdfs rsHdfsResponse) float64 {
+					return rsHdfs.ZeroCopyBytesRead
+				},
+				Labels: defaultHBaseRsHdfsLabelValues,
+			},
+		},
+
+		ioMetrics: []*rsHdfsMetric{
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "checksum_failures"),
+					"The number of HFile checksum verification failures.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+				Value: func(rsHdfs rsHdfsResponse) float64 {
+					return rsHdfs.FsChecksumFailureCount
+				},
+				Labels: defaultHBaseRsHdfsLabelValues,
+			},
+		},
+
+		histograms: []*hbaseHistogram{
+			{
+				Attr: "FsReadTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "fs_read_time_ms"),
+					"The time of HFile reads from HDFS in milliseconds.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+			},
+			{
+				Attr: "FsPReadTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "fs_pread_time_ms"),
+					"The time of HFile positional reads from HDFS in milliseconds.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+			},
+			{
+				Attr: "FsWriteTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "fs_write_time_ms"),
+					"The time of HFile writes to HDFS in milliseconds.",
+					defaultHBaseRsHdfsLabels, nil,
+				),
+			},
+		},
+	}
+}
+
+func (r *RsHdfs) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range r.serverMetrics {
+		ch <- metric.Desc
+	}
+	for _, metric := range r.ioMetrics {
+		ch <- metric.Desc
+	}
+	for _, histogram := range r.histograms {
+		ch <- histogram.Desc
+	}
+
+	ch <- r.up.Desc()
+	ch <- r.totalScrapes.Desc()
+	ch <- r.jsonParseFailures.Desc()
+}
+
+// fetchAndDecodeRsHdfs returns the first bean matching qry, both raw and
+// decoded.
+func (r *RsHdfs) fetchAndDecodeRsHdfs(qry string) (gjson.Result, rsHdfsResponse, error) {
+	var rsHdfsResp rsHdfsResponse
+
+	bts, err := fetchJmx(r.logger, r.jmx, qry)
+	if err != nil {
+		if isJmxReadError(err) {
+			r.jsonParseFailures.Inc()
+		}
+		return gjson.Result{}, rsHdfsResp, err
+	}
+
+	bean, err := firstBean(bts)
+	if err != nil {
+		if err != errNoBeans {
+			r.jsonParseFailures.Inc()
+		}
+		return gjson.Result{}, rsHdfsResp, err
+	}
+
+	if err := json.Unmarshal([]byte(bean.Raw), &rsHdfsResp); err != nil {
+		r.jsonParseFailures.Inc()
+		return gjson.Result{}, rsHdfsResp, err
+	}
+
+	return bean, rsHdfsResp, nil
+}
+
+// collectServerBean exports the hdfs metrics of the sub=Server bean fetched
+// by RsServer and those of the IO bean, or only up 0 if either fails.
+func (r *RsHdfs) collectServerBean(ch chan<- prometheus.Metric, bean gjson.Result, err error) {
+	r.totalScrapes.Inc()
+	defer func() {
+		ch <- r.up
+		ch <- r.totalScrapes
+		ch <- r.jsonParseFailures
+	}()
+
+	if err != nil {
+		r.up.Set(0)
+		return
+	}
+
+	var serverResp rsHdfsResponse
+	if err := json.Unmarshal([]byte(bean.Raw), &serverResp); err != nil {
+		r.up.Set(0)
+		r.jsonParseFailures.Inc()
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to decode hdfs metrics",
+			"bean", "Server",
+			"err", err,
+		)
+		return
+	}
+
+	for _, metric := range r.serverMetrics {
+		ch <- prometheus.MustNewConstMetric(
+			metric.Desc,
+			metric.Type,
+			metric.Value(serverResp),
+			metric.Labels(serverResp)...,
+		)
+	}
+
+	// HBase 1.x has no IO bean, which leaves only the Server bean metrics.
+	ioBean, ioResp, err := r.fetchAndDecodeRsHdfs("Hadoop:service=HBase,name=RegionServer,sub=IO")
+	if err == errNoBeans {
+		r.up.Set(1)
+		return
+	}
+	if err != nil {
+		r.up.Set(0)
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to fetch and decode hdfs metrics",
+			"bean", "IO",
+			"err", err,
+		)
+		return
+	}
+	r.up.Set(1)
+
+	for _, metric := range r.ioMetrics {
+		ch <- prometheus.MustNewConstMetric(
+			metric.Desc,
+			metric.Type,
+			metric.Value(ioResp),
+			metric.Labels(ioResp)...,
+		)
+	}
+
+	collectHistograms(ch, r.histograms, ioBean.Map(), defaultHBaseRsHdfsLabelValues(ioResp)...)
+}
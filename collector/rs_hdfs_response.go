@@ -0,0 +1,14 @@
+package collector
+
+type rsHdfsResponse struct {
+	Host                     string  `json:"tag.Hostname"`
+	Role                     string  `json:"tag.Context"`
+	HedgedReads              float64 `json:"hedgedReads"`
+	HedgedReadWins           float64 `json:"hedgedReadWins"`
+	HedgedReadOpsInCurThread float64 `json:"hedgedReadOpsInCurThread"`
+	TotalBytesRead           float64 `json:"totalBytesRead"`
+	LocalBytesRead           float64 `json:"localBytesRead"`
+	ShortCircuitBytesRead    float64 `json:"shortCircuitBytesRead"`
+	ZeroCopyBytesRead        float64 `json:"zeroCopyBytesRead"`
+	FsChecksumFailureCount   float64 `json:"fsChecksumFailureCount"`
+}
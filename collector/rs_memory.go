@@ -0,0 +1,261 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseRsMemoryLabels      = []string{"host", "role"}
+	defaultHBaseRsMemoryLabelValues = func(rsMemory rsMemoryResponse) []string {
+		return []string{
+			rsMemory.Host,
+			strings.ToLower(rsMemory.Role),
+		}
+	}
+)
+
+type rsMemoryMetric struct {
+	Type   prometheus.ValueType
+	Desc   *prometheus.Desc
+	Value  func(rsMemory rsMemoryResponse) float64
+	Labels func(rsMemory rsMemoryResponse) []string
+}
+
+// RsMemory collects the heap memory manager metrics HBase 2.x publishes
+// for a regionserver.
+type RsMemory struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	metrics    []*rsMemoryMetric
+	histograms []*hbaseHistogram
+}
+
+func NewRsMemory(logger log.Logger, jmx *JmxClient) *RsMemory {
+	subsystem := "memory"
+
+	return &RsMemory{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase memory endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase memory scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		metrics: []*rsMemoryMetric{
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "mem_store_size_bytes"),
+					"The memory used by the memstores in bytes when the heap memory tuner last ran.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+				Value: func(rsMemory rsMemoryResponse) float64 {
+					return rsMemory.MemStoreSize
+				},
+				Labels: defaultHBaseRsMemoryLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "block_cache_size_bytes"),
+					"The memory used by the block cache in bytes when the heap memory tuner last ran.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+				Value: func(rsMemory rsMemoryResponse) float64 {
+					return rsMemory.BlockCacheSize
+				},
+				Labels: defaultHBaseRsMemoryLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "blocked_flush_gauge"),
+					"The number of memstore flushes that are blocking writes.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+				Value: func(rsMemory rsMemoryResponse) float64 {
+					return rsMemory.BlockedFlushGauge
+				},
+				Labels: defaultHBaseRsMemoryLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "unblocked_flush_gauge"),
+					"The number of memstore flushes that are not blocking writes.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+				Value: func(rsMemory rsMemoryResponse) float64 {
+					return rsMemory.UnblockedFlushGauge
+				},
+				Labels: defaultHBaseRsMemoryLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "above_heap_occupancy_low_water_mark_count"),
+					"The number of times the heap occupancy was above the low water mark.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+				Value: func(rsMemory rsMemoryResponse) float64 {
+					return rsMemory.AboveHeapOccupancyLowWaterMarkCounter
+				},
+				Labels: defaultHBaseRsMemoryLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "tuner_do_nothing_count"),
+					"The number of times the heap memory tuner decided not to resize.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+				Value: func(rsMemory rsMemoryResponse) float64 {
+					return rsMemory.TunerDoNothingCounter
+				},
+				Labels: defaultHBaseRsMemoryLabelValues,
+			},
+		},
+
+		histograms: []*hbaseHistogram{
+			{
+				Attr: "blockedFlushes",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "blocked_flushes"),
+					"The number of blocking flushes per heap memory tuner period.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+			},
+			{
+				Attr: "unblockedFlushes",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "unblocked_flushes"),
+					"The number of non-blocking flushes per heap memory tuner period.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+			},
+			{
+				Attr: "increaseMemStoreSize",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "increase_mem_store_size_bytes"),
+					"The memstore size increments made by the heap memory tuner in bytes.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+			},
+			{
+				Attr: "decreaseMemStoreSize",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "decrease_mem_store_size_bytes"),
+					"The memstore size decrements made by the heap memory tuner in bytes.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+			},
+			{
+				Attr: "increaseBlockCacheSize",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "increase_block_cache_size_bytes"),
+					"The block cache size increments made by the heap memory tuner in bytes.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+			},
+			{
+				Attr: "decreaseBlockCacheSize",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "decrease_block_cache_size_bytes"),
+					"The block cache size decrements made by the heap memory tuner in bytes.",
+					defaultHBaseRsMemoryLabels, nil,
+				),
+			},
+		},
+	}
+}
+
+func (r *RsMemory) upGauge() prometheus.Gauge {
+	return r.up
+}
+
+func (r *RsMemory) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range r.metrics {
+		ch <- metric.Desc
+	}
+	for _, histogram := range r.histograms {
+		ch <- histogram.Desc
+	}
+
+	ch <- r.up.Desc()
+	ch <- r.totalScrapes.Desc()
+	ch <- r.jsonParseFailures.Desc()
+}
+
+func (r *RsMemory) Collect(ch chan<- prometheus.Metric) {
+	r.totalScrapes.Inc()
+	defer func() {
+		ch <- r.up
+		ch <- r.totalScrapes
+		ch <- r.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(r.logger, r.jmx, "Hadoop:service=HBase,name=RegionServer,sub=Memory")
+	if err != nil {
+		if isJmxReadError(err) {
+			r.jsonParseFailures.Inc()
+		}
+		r.up.Set(0)
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to fetch memory metrics",
+			"err", err,
+		)
+		return
+	}
+
+	bean, err := firstBean(bts)
+	if err != nil {
+		r.up.Set(0)
+		r.jsonParseFailures.Inc()
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to decode memory metrics",
+			"err", err,
+		)
+		return
+	}
+
+	var rsMemoryResp rsMemoryResponse
+	if err := json.Unmarshal([]byte(bean.Raw), &rsMemoryResp); err != nil {
+		r.up.Set(0)
+		r.jsonParseFailures.Inc()
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to decode memory metrics",
+			"err", err,
+		)
+		return
+	}
+	r.up.Set(1)
+
+	for _, metric := range r.metrics {
+		ch <- prometheus.MustNewConstMetric(
+			metric.Desc,
+			metric.Type,
+			metric.Value(rsMemoryResp),
+			metric.Labels(rsMemoryResp)...,
+		)
+	}
+
+	collectHistograms(ch, r.histograms, bean.Map(), defaultHBaseRsMemoryLabelValues(rsMemoryResp)...)
+}
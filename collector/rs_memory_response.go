@@ -0,0 +1,12 @@
+package collector
+
+type rsMemoryResponse struct {
+	Host                                  string  `json:"tag.Hostname"`
+	Role                                  string  `json:"tag.Context"`
+	MemStoreSize                          float64 `json:"memStoreSize"`
+	BlockCacheSize                        float64 `json:"blockCacheSize"`
+	BlockedFlushGauge                     float64 `json:"blockedFlushGauge"`
+	UnblockedFlushGauge                   float64 `json:"unblockedFlushGauge"`
+	AboveHeapOccupancyLowWaterMarkCounter float64 `json:"aboveHeapOccupancyLowWaterMarkCounter"`
+	TunerDoNothingCounter                 float64 `json:"tunerDoNothingCounter"`
+}
@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tidwall/gjson"
+)
+
+var defaultHBaseRsMobLabels = []string{"host", "role"}
+
+type rsMobMetric struct {
+	Type prometheus.ValueType
+	Desc *prometheus.Desc
+	// OldAttr is the attribute read when the one the metric is keyed by is
+	// missing, for counters HBase 2.x renamed.
+	OldAttr string
+}
+
+// RsMob collects the MOB (medium object) metrics of the Server bean of a
+// regionserver. Only the attributes present are exported, so regionservers
+// without MOB support export none.
+type RsMob struct {
+	logger log.Logger
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	metrics map[string]*rsMobMetric
+}
+
+func NewRsMob(logger log.Logger) *RsMob {
+	subsystem := "mob"
+	newMetric := func(valueType prometheus.ValueType, name, help string) *rsMobMetric {
+		return &rsMobMetric{
+			Type: valueType,
+			Desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, name),
+				help,
+				defaultHBaseRsMobLabels, nil,
+			),
+		}
+	}
+	newRenamedMetric := func(oldAttr, name, help string) *rsMobMetric {
+		metric := newMetric(prometheus.CounterValue, name, help)
+		metric.OldAttr = oldAttr
+		return metric
+	}
+
+	return &RsMob{
+		logger: logger,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase mob endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase mob scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		metrics: map[string]*rsMobMetric{
+			"mobFlushCount":              newMetric(prometheus.CounterValue, "flush_count", "The number of memstore flushes that wrote MOB files."),
+			"mobFlushedCellsCount":       newMetric(prometheus.CounterValue, "flushed_cells_count", "The number of cells flushed into MOB files."),
+			"mobFlushedCellsSize":        newMetric(prometheus.CounterValue, "flushed_cells_size_bytes", "The size of the cells flushed into MOB files in bytes."),
+			"mobScanCellsCount":          newMetric(prometheus.CounterValue, "scan_cells_count", "The number of cells read from MOB files by scans."),
+			"mobScanCellsSize":           newMetric(prometheus.CounterValue, "scan_cells_size_bytes", "The size of the cells read from MOB files by scans in bytes."),
+			"mobFileCacheHitPercent":     newMetric(prometheus.GaugeValue, "file_cache_hit_percent", "The percentage of MOB file opens served by the MOB file cache."),
+			"mobFileCacheAccessCount":    newMetric(prometheus.CounterValue, "file_cache_access_count", "The number of MOB file cache accesses."),
+			"mobFileCacheMissCount":      newMetric(prometheus.CounterValue, "file_cache_miss_count", "The number of MOB file cache misses."),
+			"mobFileCacheEvictedCount":   newMetric(prometheus.CounterValue, "file_cache_evicted_count", "The number of MOB files evicted from the MOB file cache."),
+			"mobFileCacheCount":          newMetric(prometheus.GaugeValue, "file_cache_count", "The number of MOB files in the MOB file cache."),
+			"cellsCountCompactedToMob":   newRenamedMetric("mobCompactedIntoMobCellsCount", "compacted_into_mob_cells_count", "The number of cells moved into MOB files by compactions."),
+			"cellsCountCompactedFromMob": newRenamedMetric("mobCompactedFromMobCellsCount", "compacted_from_mob_cells_count", "The number of cells moved out of MOB files by compactions."),
+			"cellsSizeCompactedToMob":    newRenamedMetric("mobCompactedIntoMobCellsSize", "compacted_into_mob_cells_size_bytes", "The size of the cells moved into MOB files by compactions in bytes."),
+			"cellsSizeCompactedFromMob":  newRenamedMetric("mobCompactedFromMobCellsSize", "compacted_from_mob_cells_size_bytes", "The size of the cells moved out of MOB files by compactions in bytes."),
+		},
+	}
+}
+
+func (r *RsMob) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range r.metrics {
+		ch <- metric.Desc
+	}
+
+	ch <- r.up.Desc()
+	ch <- r.totalScrapes.Desc()
+	ch <- r.jsonParseFailures.Desc()
+}
+
+// collectServerBean exports the MOB metrics of the sub=Server bean
+// fetched by RsServer, or only up 0 if fetching it failed.
+func (r *RsMob) collectServerBean(ch chan<- prometheus.Metric, bean gjson.Result, err error) {
+	r.totalScrapes.Inc()
+	defer func() {
+		ch <- r.up
+		ch <- r.totalScrapes
+		ch <- r.jsonParseFailures
+	}()
+
+	if err != nil {
+		r.up.Set(0)
+		return
+	}
+	r.up.Set(1)
+
+	attrs := bean.Map()
+	labels := []string{attrs["tag.Hostname"].String(), strings.ToLower(RegionServerService)}
+
+	for attr, metric := range r.metrics {
+		v, ok := attrs[attr]
+		if !ok && metric.OldAttr != "" {
+			v, ok = attrs[metric.OldAttr]
+		}
+		if !ok {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			metric.Desc,
+			metric.Type,
+			v.Float(),
+			labels...,
+		)
+	}
+}
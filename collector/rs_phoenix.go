@@ -0,0 +1,199 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseRsPhoenixLabels = []string{"host", "role"}
+
+	// hbasePhoenixIndexUpdateFailures maps the index update failure
+	// counters of the PhoenixIndexer bean to the phase label.
+	hbasePhoenixIndexUpdateFailures = map[string]string{
+		"preIndexUpdateFailure":  "pre",
+		"postIndexUpdateFailure": "post",
+	}
+)
+
+type rsPhoenixMetric struct {
+	Type prometheus.ValueType
+	Desc *prometheus.Desc
+}
+
+// RsPhoenix collects the index metrics the Phoenix coprocessors publish on a
+// regionserver: the PhoenixIndexer bean of the index writer and the
+// GlobalIndexChecker bean of the read repair of global indexes. Regionservers
+// without Phoenix have neither bean, so the collector only reports
+// hbase_phoenix_enabled 0 there.
+type RsPhoenix struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	enabled             *prometheus.Desc
+	indexUpdateFailures *prometheus.Desc
+	metrics             map[string]*rsPhoenixMetric
+	histograms          []*hbaseHistogram
+}
+
+func NewRsPhoenix(logger log.Logger, jmx *JmxClient) *RsPhoenix {
+	subsystem := "phoenix"
+	newMetric := func(valueType prometheus.ValueType, name, help string) *rsPhoenixMetric {
+		return &rsPhoenixMetric{
+			Type: valueType,
+			Desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, name),
+				help,
+				defaultHBaseRsPhoenixLabels, nil,
+			),
+		}
+	}
+	newHistogram := func(attr, name, help string) *hbaseHistogram {
+		return &hbaseHistogram{
+			Attr: attr,
+			Desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, name),
+				help,
+				defaultHBaseRsPhoenixLabels, nil,
+			),
+		}
+	}
+	return &RsPhoenix{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase phoenix endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase phoenix scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		enabled: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "enabled"),
+			"Whether the regionserver publishes Phoenix index metrics.",
+			defaultHBaseRsPhoenixLabels, nil,
+		),
+		indexUpdateFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "index_update_failures"),
+			"The number of index updates that failed, by phase.",
+			append(defaultHBaseRsPhoenixLabels, "phase"), nil,
+		),
+		metrics: map[string]*rsPhoenixMetric{
+			"slowIndexPrepareCalls": newMetric(prometheus.CounterValue, "slow_index_prepare_calls", "The number of slow index prepare calls."),
+			"slowIndexWriteCalls":   newMetric(prometheus.CounterValue, "slow_index_write_calls", "The number of slow index write calls."),
+			"indexInspections":      newMetric(prometheus.CounterValue, "index_inspections", "The number of unverified global index rows inspected."),
+			"indexRepairs":          newMetric(prometheus.CounterValue, "index_repairs", "The number of global index rows repaired from the data table."),
+			"indexRepairFailures":   newMetric(prometheus.CounterValue, "index_repair_failures", "The number of global index row repairs that failed."),
+		},
+		histograms: []*hbaseHistogram{
+			newHistogram("indexPrepareTime", "index_prepare_time_ms", "The time to prepare index updates in milliseconds."),
+			newHistogram("indexWriteTime", "index_write_time_ms", "The time to write index updates in milliseconds."),
+			newHistogram("indexRepairTime", "index_repair_time_ms", "The time to repair global index rows in milliseconds."),
+		},
+	}
+}
+
+func (r *RsPhoenix) upGauge() prometheus.Gauge {
+	return r.up
+}
+
+func (r *RsPhoenix) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.enabled
+	ch <- r.indexUpdateFailures
+	for _, metric := range r.metrics {
+		ch <- metric.Desc
+	}
+	for _, histogram := range r.histograms {
+		ch <- histogram.Desc
+	}
+
+	ch <- r.up.Desc()
+	ch <- r.totalScrapes.Desc()
+	ch <- r.jsonParseFailures.Desc()
+}
+
+func (r *RsPhoenix) Collect(ch chan<- prometheus.Metric) {
+	r.totalScrapes.Inc()
+	defer func() {
+		ch <- r.up
+		ch <- r.totalScrapes
+		ch <- r.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(r.logger, r.jmx, "Hadoop:service=HBase,name=RegionServer,sub=*Index*")
+	if err != nil {
+		if isJmxReadError(err) {
+			r.jsonParseFailures.Inc()
+		}
+		r.up.Set(0)
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to fetch phoenix metrics",
+			"err", err,
+		)
+		return
+	}
+
+	beans, err := allBeans(bts)
+	if err != nil && err != errNoBeans {
+		r.up.Set(0)
+		r.jsonParseFailures.Inc()
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to decode phoenix metrics",
+			"err", err,
+		)
+		return
+	}
+	r.up.Set(1)
+
+	role := strings.ToLower(RegionServerService)
+
+	host, enabled := "", 0.0
+	if len(beans) > 0 {
+		host, enabled = beans[0].Map()["tag.Hostname"].String(), 1
+	} else {
+		host = r.jmx.host()
+	}
+	ch <- prometheus.MustNewConstMetric(r.enabled, prometheus.GaugeValue, enabled, host, role)
+
+	for _, bean := range beans {
+		attrs := bean.Map()
+		labels := []string{attrs["tag.Hostname"].String(), role}
+
+		for attr, metric := range r.metrics {
+			if v, ok := attrs[attr]; ok {
+				ch <- prometheus.MustNewConstMetric(
+					metric.Desc,
+					metric.Type,
+					v.Float(),
+					labels...,
+				)
+			}
+		}
+
+		for attr, phase := range hbasePhoenixIndexUpdateFailures {
+			if v, ok := attrs[attr]; ok {
+				ch <- prometheus.MustNewConstMetric(
+					r.indexUpdateFailures,
+					prometheus.CounterValue,
+					v.Float(),
+					append(labels, phase)...,
+				)
+			}
+		}
+
+		collectHistograms(ch, r.histograms, attrs, labels...)
+	}
+}
@@ -1,12 +1,9 @@
 package collector
 
 import (
-	"fmt"
-	"io/ioutil"
 	"sync"
+	"time"
 
-	"net/http"
-	"net/url"
 	"strings"
 
 	"../utils"
@@ -20,244 +17,363 @@ var (
 	defaultHBaseRsRegionLabels = []string{"host", "role", "namespace", "htable", "hregion"}
 )
 
-type hbaseRegionJmxMetric struct {
+// hbaseRegion holds the sub=Regions attributes of one region, keyed by
+// the metric suffix of Namespace_<n>_table_<t>_region_<r>_metric_<m>.
+type hbaseRegion struct {
 	Namespace string
 	Table     string
 	Region    string
-	Metric    string
-	Value     float64
+	Attrs     map[string]gjson.Result
+}
+
+type rsRegionMetric struct {
+	Type prometheus.ValueType
+	Desc *prometheus.Desc
+}
+
+// RsRegionOptions configures the optional parts of RsRegion.
+type RsRegionOptions struct {
+	// UnknownAttributes exports the region attributes that have no entry in
+	// the metric table as hbase_region_attribute{name=...}.
+	UnknownAttributes bool
+	// Hotspots exports per-region request rates computed between scrapes,
+	// each region's share of the server's traffic and the HotspotTopK
+	// busiest regions.
+	Hotspots    bool
+	HotspotTopK int
+	// Lifecycle exports the number of regions opened and closed on the
+	// server and the age of each region as first seen by the exporter.
+	// LogRegionChanges additionally logs every region that was opened,
+	// closed or split.
+	Lifecycle        bool
+	LogRegionChanges bool
+	// PhoenixLabels adds the phoenix_schema and phoenix_table labels to the
+	// region metrics, split from Phoenix table names such as SCHEMA.TABLE.
+	PhoenixLabels bool
 }
 
 type RsRegion struct {
 	logger log.Logger
-	url    *url.URL
+	jmx    *JmxClient
+	opts   RsRegionOptions
+
+	up prometheus.Gauge
 
-	metrics map[string]*prometheus.Desc
-	mutex   sync.Mutex
+	metrics    map[string]*rsRegionMetric
+	histograms []*hbaseHistogram
+	attribute  *prometheus.Desc
+	tables     map[string]*prometheus.Desc
+	hotspots   *rsRegionHotspotMetrics
+	lifecycle  *rsRegionLifecycleMetrics
+	mutex      sync.Mutex
 
-	jmxs []*hbaseRegionJmxMetric
+	samples map[string]*hbaseRegionSample
+
+	// lastFetch is the time of the fetch the hotspot samples and the
+	// lifecycle state were last updated from.
+	lastFetch time.Time
+
+	lastRegions      map[string]*hbaseRegion
+	firstSeen        map[string]time.Time
+	lifecycleStarted bool
 }
 
-func newMetric(metric string, doc string) *prometheus.Desc {
+func newMetric(metric string, doc string, labels []string) *prometheus.Desc {
 	subsystem := "region"
 
 	return prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, subsystem, metric),
 		doc,
-		defaultHBaseRsRegionLabels,
+		labels,
 		nil,
 	)
 }
 
-func NewRsRegion(logger log.Logger, url *url.URL) *RsRegion {
+func NewRsRegion(logger log.Logger, jmx *JmxClient, opts RsRegionOptions) *RsRegion {
+	labels := defaultHBaseRsRegionLabels
+	if opts.PhoenixLabels {
+		labels = append(labels[:len(labels):len(labels)], "phoenix_schema", "phoenix_table")
+	}
+
 	return &RsRegion{
 		logger: logger,
-		url:    url,
-
-		metrics: map[string]*prometheus.Desc{
-			"read_request_count":          newMetric("read_request_count", "The description of read_request_count"),
-			"write_request_count":         newMetric("write_request_count", "The description of write_request_count"),
-			"store_count":                 newMetric("store_count", "The description of store_count"),
-			"store_file_count":            newMetric("store_file_count", "The description of store_file_count"),
-			"mem_store_size":              newMetric("mem_store_size", "The description of mem_store_size"),
-			"store_file_size":             newMetric("store_file_size", "The description of store_file_size"),
-			"compactions_completed_count": newMetric("compactions_completed_count", "The description of compactions_completed_count"),
-			"num_bytes_compacted_count":   newMetric("num_bytes_compacted_count", "The description of numBytes_compacted_count"),
-			"num_files_compacted_count":   newMetric("num_files_compacted_count", "The description of num_files_compacted_count"),
+		jmx:    jmx,
+		opts:   opts,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, "region", "up"),
+			Help: "Was the last scrape of the HBase regions endpoint successful.",
+		}),
+
+		// metrics maps the metric suffix of a region attribute to the
+		// metric it is exported as.
+		metrics: map[string]*rsRegionMetric{
+			"readRequestCount":              {prometheus.CounterValue, newMetric("read_request_count", "The number of read requests to the region.", labels)},
+			"filteredReadRequestCount":      {prometheus.CounterValue, newMetric("filtered_read_request_count", "The number of read requests to the region that were filtered out.", labels)},
+			"writeRequestCount":             {prometheus.CounterValue, newMetric("write_request_count", "The number of write requests to the region.", labels)},
+			"cpRequestCount":                {prometheus.CounterValue, newMetric("cp_request_count", "The number of coprocessor service requests to the region.", labels)},
+			"appendCount":                   {prometheus.CounterValue, newMetric("append_count", "The number of appends to the region.", labels)},
+			"deleteCount":                   {prometheus.CounterValue, newMetric("delete_count", "The number of deletes to the region.", labels)},
+			"incrementCount":                {prometheus.CounterValue, newMetric("increment_count", "The number of increments to the region.", labels)},
+			"mutateCount":                   {prometheus.CounterValue, newMetric("mutate_count", "The number of mutations to the region.", labels)},
+			"storeCount":                    {prometheus.GaugeValue, newMetric("store_count", "The number of stores of the region.", labels)},
+			"storeFileCount":                {prometheus.GaugeValue, newMetric("store_file_count", "The number of store files of the region.", labels)},
+			"memStoreSize":                  {prometheus.GaugeValue, newMetric("mem_store_size", "The size of the memstores of the region in bytes.", labels)},
+			"storeFileSize":                 {prometheus.GaugeValue, newMetric("store_file_size", "The size of the store files of the region in bytes.", labels)},
+			"maxStoreFileAge":               {prometheus.GaugeValue, newMetric("max_store_file_age_ms", "The age of the oldest store file of the region in milliseconds.", labels)},
+			"minStoreFileAge":               {prometheus.GaugeValue, newMetric("min_store_file_age_ms", "The age of the newest store file of the region in milliseconds.", labels)},
+			"avgStoreFileAge":               {prometheus.GaugeValue, newMetric("avg_store_file_age_ms", "The average age of the store files of the region in milliseconds.", labels)},
+			"numReferenceFiles":             {prometheus.GaugeValue, newMetric("num_reference_files", "The number of reference files of the region.", labels)},
+			"storeRefCount":                 {prometheus.GaugeValue, newMetric("store_ref_count", "The number of references to the stores of the region.", labels)},
+			"maxCompactedStoreFileRefCount": {prometheus.GaugeValue, newMetric("max_compacted_store_file_ref_count", "The highest reference count of a compacted store file of the region.", labels)},
+			"compactionsCompletedCount":     {prometheus.CounterValue, newMetric("compactions_completed_count", "The number of compactions completed on the region.", labels)},
+			"compactionsFailedCount":        {prometheus.CounterValue, newMetric("compactions_failed_count", "The number of compactions failed on the region.", labels)},
+			"numBytesCompactedCount":        {prometheus.CounterValue, newMetric("num_bytes_compacted_count", "The number of bytes compacted on the region.", labels)},
+			"numFilesCompactedCount":        {prometheus.CounterValue, newMetric("num_files_compacted_count", "The number of files compacted on the region.", labels)},
+			"compactionsQueuedCount":        {prometheus.GaugeValue, newMetric("compactions_queued_count", "The number of compactions queued for the region.", labels)},
+			"maxCompactionQueueSize":        {prometheus.GaugeValue, newMetric("max_compaction_queue_size", "The largest number of compactions queued for the region at once.", labels)},
+			"lastMajorCompactionAge":        {prometheus.GaugeValue, newMetric("last_major_compaction_age_ms", "The time since the last major compaction of the region in milliseconds.", labels)},
+			"replicaid":                     {prometheus.GaugeValue, newMetric("replica_id", "The replica id of the region.", labels)},
+			"dataLocality":                  {prometheus.GaugeValue, newMetric("data_locality", "The fraction of the store file data of the region that is local to the regionserver.", labels)},
 		},
 
-		jmxs: []*hbaseRegionJmxMetric{},
+		histograms: []*hbaseHistogram{
+			{Attr: "get", Desc: newMetric("get_time_ms", "The time of gets on the region in milliseconds.", labels)},
+			{Attr: "scanTime", Desc: newMetric("scan_time_ms", "The time of scans on the region in milliseconds.", labels)},
+			{Attr: "scanSize", Desc: newMetric("scan_size_bytes", "The size of scan results on the region in bytes.", labels)},
+			{Attr: "scanNext", Desc: newMetric("scan_next_size_bytes", "The size of scan next results on the region in bytes.", labels)},
+		},
+
+		attribute: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "region", "attribute"),
+			"The value of a region attribute without a dedicated metric.",
+			append(labels[:len(labels):len(labels)], "name"), nil,
+		),
+		// tables holds the per-table rollups of the region metrics.
+		tables: map[string]*prometheus.Desc{
+			"oldest_major_compaction_age_ms": prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "table", "oldest_major_compaction_age_ms"),
+				"The time since the last major compaction of the least recently major compacted region of the table on the regionserver in milliseconds.",
+				[]string{"host", "role", "namespace", "htable"}, nil,
+			),
+			"locality": prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "table", "locality"),
+				"The data locality of the regions of the table on the regionserver, weighted by store file size.",
+				[]string{"host", "role", "namespace", "htable"}, nil,
+			),
+		},
+		hotspots:  newRsRegionHotspotMetrics(labels),
+		lifecycle: newRsRegionLifecycleMetrics(labels),
+
+		samples: map[string]*hbaseRegionSample{},
+
+		lastRegions: map[string]*hbaseRegion{},
+		firstSeen:   map[string]time.Time{},
 	}
 }
 
+func (r *RsRegion) upGauge() prometheus.Gauge {
+	return r.up
+}
+
 func (m *RsRegion) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.up.Desc()
 	for _, metric := range m.metrics {
-		ch <- metric
+		ch <- metric.Desc
+	}
+	for _, histogram := range m.histograms {
+		ch <- histogram.Desc
+	}
+	for _, table := range m.tables {
+		ch <- table
+	}
+	if m.opts.UnknownAttributes {
+		ch <- m.attribute
+	}
+	if m.opts.Hotspots {
+		m.hotspots.describe(ch)
+	}
+	if m.opts.Lifecycle {
+		m.lifecycle.describe(ch)
 	}
 }
 
-func (r *RsRegion) fetchAndDecodeRsRegion() (string, string, error) {
-	u := *r.url
-	r.jmxs = r.jmxs[0:0]
-	url := u.String() + "?" + "qry=Hadoop:service=HBase,name=RegionServer,sub=Regions"
-	res, err := http.Get(url)
-
-	if err != nil {
-		return "", "", fmt.Errorf("failed to get cluster health from %s://%s:%s%s: %s",
-			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
-	}
+func (r *RsRegion) fetchAndDecodeRsRegion() (string, string, map[string]*hbaseRegion, error) {
+	var host, role string
+	regions := map[string]*hbaseRegion{}
+
+	err := streamFirstBean(r.logger, r.jmx, "Hadoop:service=HBase,name=RegionServer,sub=Regions", func(k string, v gjson.Result) {
+		switch {
+		case k == "tag.Hostname":
+			host = v.String()
+		case k == "tag.Context":
+			role = v.String()
+		case strings.HasPrefix(k, "Namespace"):
+			keys := utils.SplitHBaseRegionStr(k)
 
-	defer func() {
-		err = res.Body.Close()
-		if err != nil {
-			_ = level.Warn(r.logger).Log(
-				"msg", "failed to close http.Client",
-				"err", err,
-			)
+			key := keys[0] + "," + keys[1] + "," + keys[2]
+			region, ok := regions[key]
+			if !ok {
+				region = &hbaseRegion{
+					Namespace: keys[0],
+					Table:     keys[1],
+					Region:    keys[2],
+					Attrs:     map[string]gjson.Result{},
+				}
+				regions[key] = region
+			}
+			region.Attrs[keys[3]] = v
 		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return "", "", fmt.Errorf("HTTP Request failed with code %d", res.StatusCode)
-	}
-
-	bts, err := ioutil.ReadAll(res.Body)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
 	}
 
-	data := gjson.Get(string(bts), "beans").Array()[0].Map()
+	return host, role, regions, nil
 
-	host := data["tag.Hostname"].String()
-	role := data["tag.Context"].String()
+}
 
-	for k, v := range data {
-		if strings.HasPrefix(k, "Namespace") {
-			keys := utils.SplitHBaseRegionStr(k)
-			values := v.Float()
+// regionLabels returns the label values of the region metrics of region.
+func (r *RsRegion) regionLabels(host, role string, region *hbaseRegion) []string {
+	labels := []string{host, role, region.Namespace, region.Table, region.Region}
+	if r.opts.PhoenixLabels {
+		labels = append(labels, phoenixTableName(region.Table)...)
+	}
+	return labels
+}
 
-			r.jmxs = append(r.jmxs, &hbaseRegionJmxMetric{
-				keys[0],
-				keys[1],
-				keys[2],
-				keys[3],
-				values,
-			})
+// phoenixTableName splits the HBase table of a Phoenix table, SCHEMA.TABLE,
+// into its schema and table. Tables without a schema have an empty schema.
+func phoenixTableName(table string) []string {
+	if i := strings.Index(table, "."); i >= 0 {
+		return []string{table[:i], table[i+1:]}
+	}
+	return []string{"", table}
+}
 
+// isHistogramAttr reports whether attr belongs to one of the region
+// histograms, e.g. get_num_ops or scanTime_99th_percentile.
+func (r *RsRegion) isHistogramAttr(attr string) bool {
+	for _, histogram := range r.histograms {
+		if strings.HasPrefix(attr, histogram.Attr+"_") {
+			return true
 		}
 	}
-
-	return host, role, nil
-
+	return false
 }
 
 func (r *RsRegion) Collect(ch chan<- prometheus.Metric) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
 	var err error
 
-	host, role, err := r.fetchAndDecodeRsRegion()
+	fetched := time.Now()
+	host, role, regions, err := r.fetchAndDecodeRsRegion()
 
 	if err != nil {
+		r.up.Set(0)
+		ch <- r.up
 		_ = level.Warn(r.logger).Log(
 			"msg", "failed to fetch and decode cluster health",
 			"err", err,
 		)
 		return
 	}
+	r.up.Set(1)
+	ch <- r.up
 
-	for _, jmx := range r.jmxs {
-		Type := prometheus.GaugeValue
-		Value := jmx.Value
-		Labels := []string{host, role, jmx.Namespace, jmx.Table, jmx.Region}
+	role = strings.ToLower(role)
 
-		if jmx.Metric == "storeCount" {
-			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "", "store_count"),
-					"The number of store_count.",
-					defaultHBaseRsRegionLabels, nil,
-				),
-				Type,
-				Value,
-				Labels...,
-			)
-		} else if jmx.Metric == "storeFileCount" {
-			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "", "store_file_count"),
-					"The number of store_file_count.",
-					defaultHBaseRsRegionLabels, nil,
-				),
-				Type,
-				Value,
-				Labels...,
-			)
+	oldestMajorCompaction := map[[2]string]float64{}
+	localBytes := map[[2]string]float64{}
+	storeFileBytes := map[[2]string]float64{}
 
-		} else if jmx.Metric == "memStoreSize" {
-			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "", "mem_store_size"),
-					"The number of mem_store_size.",
-					defaultHBaseRsRegionLabels, nil,
-				),
-				Type,
-				Value,
-				Labels...,
-			)
-		} else if jmx.Metric == "storeFileSize" {
-			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "", "store_file_size"),
-					"The number of store_file_size.",
-					defaultHBaseRsRegionLabels, nil,
-				),
-				Type,
-				Value,
-				Labels...,
-			)
+	for _, region := range regions {
+		labels := r.regionLabels(host, role, region)
 
-		} else if jmx.Metric == "compactionsCompletedCount" {
-			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "", "compactions_completed_count"),
-					"The number of compactions_completed_count.",
-					defaultHBaseRsRegionLabels, nil,
-				),
-				Type,
-				Value,
-				Labels...,
-			)
+		if v, ok := region.Attrs["lastMajorCompactionAge"]; ok {
+			table := [2]string{region.Namespace, region.Table}
+			if age, seen := oldestMajorCompaction[table]; !seen || v.Float() > age {
+				oldestMajorCompaction[table] = v.Float()
+			}
+		}
 
-		} else if jmx.Metric == "readRequestCount" {
-			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "", "read_request_count"),
-					"The number of read_request_count.",
-					defaultHBaseRsRegionLabels, nil,
-				),
-				Type,
-				Value,
-				Labels...,
-			)
+		if v, ok := region.Attrs["dataLocality"]; ok {
+			table := [2]string{region.Namespace, region.Table}
+			size := region.Attrs["storeFileSize"].Float()
+			localBytes[table] += v.Float() * size
+			storeFileBytes[table] += size
+		}
 
-		} else if jmx.Metric == "writeRequestCount" {
-			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "", "write_request_count"),
-					"The number of write_request_count.",
-					defaultHBaseRsRegionLabels, nil,
-				),
-				Type,
-				Value,
-				Labels...,
-			)
+		for attr, v := range region.Attrs {
+			if metric, ok := r.metrics[attr]; ok {
+				ch <- prometheus.MustNewConstMetric(
+					metric.Desc,
+					metric.Type,
+					v.Float(),
+					labels...,
+				)
+				continue
+			}
+
+			if !r.opts.UnknownAttributes || r.isHistogramAttr(attr) {
+				continue
+			}
 
-		} else if jmx.Metric == "numFilesCompactedCount" {
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "", "num_files_compacted_count"),
-					"The number of num_files_compacted_count.",
-					defaultHBaseRsRegionLabels, nil,
-				),
-				Type,
-				Value,
-				Labels...,
+				r.attribute,
+				prometheus.UntypedValue,
+				v.Float(),
+				append(labels, attr)...,
 			)
+		}
 
-		} else if jmx.Metric == "numBytesCompactedCount" {
-			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "", "num_bytes_compacted_count"),
-					"The number of num_bytes_compacted_count.",
-					defaultHBaseRsRegionLabels, nil,
-				),
-				Type,
-				Value,
-				Labels...,
-			)
+		collectHistograms(ch, r.histograms, region.Attrs, labels...)
+	}
+
+	for table, age := range oldestMajorCompaction {
+		ch <- prometheus.MustNewConstMetric(
+			r.tables["oldest_major_compaction_age_ms"],
+			prometheus.GaugeValue,
+			age,
+			host, role, table[0], table[1],
+		)
+	}
+
+	for table, size := range storeFileBytes {
+		// A table without store files yet is fully local.
+		locality := 1.0
+		if size > 0 {
+			locality = localBytes[table] / size
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			r.tables["locality"],
+			prometheus.GaugeValue,
+			locality,
+			host, role, table[0], table[1],
+		)
+	}
+
+	// Only the hotspot samples and the lifecycle state are shared between
+	// scrapes, so concurrent scrapes fetch and emit the regions in parallel
+	// and only serialize here.
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 
+	// A fetch that finished after a later one must not roll the state back,
+	// so it only emits the lifecycle counters.
+	if fetched.Before(r.lastFetch) {
+		if r.opts.Lifecycle {
+			r.lifecycle.opened.Collect(ch)
+			r.lifecycle.closed.Collect(ch)
 		}
+		return
+	}
+	r.lastFetch = fetched
+
+	if r.opts.Hotspots {
+		r.collectHotspots(ch, host, role, regions, fetched)
+	}
+	if r.opts.Lifecycle {
+		r.collectLifecycle(ch, host, role, regions, fetched)
 	}
 }
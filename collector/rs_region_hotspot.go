@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// hbaseRegionSample is the request counters of a region at one scrape,
+// kept to compute request rates on the next one.
+type hbaseRegionSample struct {
+	Time  time.Time
+	Read  float64
+	Write float64
+}
+
+type hbaseRegionRate struct {
+	Region *hbaseRegion
+	Read   float64
+	Write  float64
+}
+
+type rsRegionHotspotMetrics struct {
+	readRate  *prometheus.Desc
+	writeRate *prometheus.Desc
+	score     *prometheus.Desc
+	hottest   *prometheus.Desc
+}
+
+func newRsRegionHotspotMetrics(labels []string) *rsRegionHotspotMetrics {
+	return &rsRegionHotspotMetrics{
+		readRate:  newMetric("read_request_rate", "The read requests per second to the region since the previous scrape.", labels),
+		writeRate: newMetric("write_request_rate", "The write requests per second to the region since the previous scrape.", labels),
+		score:     newMetric("hotspot_score", "The share of the requests per second of the regionserver served by the region.", labels),
+		hottest: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "region", "hottest_request_rate"),
+			"The requests per second to the busiest regions of the regionserver, by rank.",
+			append(labels[:len(labels):len(labels)], "rank"), nil,
+		),
+	}
+}
+
+func (h *rsRegionHotspotMetrics) describe(ch chan<- *prometheus.Desc) {
+	ch <- h.readRate
+	ch <- h.writeRate
+	ch <- h.score
+	ch <- h.hottest
+}
+
+// collectHotspots computes the request rates of each region against the
+// previous scrape and emits them along with each region's share of the
+// server's traffic and the topK busiest regions. Regions seen for the first
+// time, or whose counters went backwards because they were reopened, get
+// no rate until the next scrape.
+func (r *RsRegion) collectHotspots(ch chan<- prometheus.Metric, host, role string, regions map[string]*hbaseRegion, now time.Time) {
+	samples := make(map[string]*hbaseRegionSample, len(regions))
+	rates := make([]*hbaseRegionRate, 0, len(regions))
+	var total float64
+
+	for key, region := range regions {
+		sample := &hbaseRegionSample{
+			Time:  now,
+			Read:  region.Attrs["readRequestCount"].Float(),
+			Write: region.Attrs["writeRequestCount"].Float(),
+		}
+		samples[key] = sample
+
+		prev, ok := r.samples[key]
+		if !ok || sample.Read < prev.Read || sample.Write < prev.Write {
+			continue
+		}
+
+		elapsed := now.Sub(prev.Time).Seconds()
+		if elapsed <= 0 {
+			continue
+		}
+
+		rate := &hbaseRegionRate{
+			Region: region,
+			Read:   (sample.Read - prev.Read) / elapsed,
+			Write:  (sample.Write - prev.Write) / elapsed,
+		}
+		rates = append(rates, rate)
+		total += rate.Read + rate.Write
+	}
+	r.samples = samples
+
+	for _, rate := range rates {
+		labels := r.regionLabels(host, role, rate.Region)
+
+		ch <- prometheus.MustNewConstMetric(r.hotspots.readRate, prometheus.GaugeValue, rate.Read, labels...)
+		ch <- prometheus.MustNewConstMetric(r.hotspots.writeRate, prometheus.GaugeValue, rate.Write, labels...)
+
+		var score float64
+		if total > 0 {
+			score = (rate.Read + rate.Write) / total
+		}
+		ch <- prometheus.MustNewConstMetric(r.hotspots.score, prometheus.GaugeValue, score, labels...)
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Read+rates[i].Write > rates[j].Read+rates[j].Write
+	})
+	for i, rate := range rates {
+		if i >= r.opts.HotspotTopK {
+			break
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			r.hotspots.hottest,
+			prometheus.GaugeValue,
+			rate.Read+rate.Write,
+			append(r.regionLabels(host, role, rate.Region), strconv.Itoa(i+1))...,
+		)
+	}
+}
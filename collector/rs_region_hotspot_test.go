@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestRsRegionHotspots(t *testing.T) {
+	// Between the fixtures, 10 seconds apart, region 0a5e8f3c served 100
+	// reads and 1b6f9a4d 20 writes per second, the counters of 5fad3e8b
+	// went backwards and the other regions are new or closed.
+	for _, test := range []struct {
+		name   string
+		topK   int
+		files  []string
+		want   map[string]float64
+		absent []string
+	}{
+		{
+			name:  "first scrape",
+			topK:  2,
+			files: []string{"regions_before.json"},
+			absent: []string{
+				"hbase_region_read_request_rate",
+				"hbase_region_hotspot_score",
+				"hbase_region_hottest_request_rate",
+			},
+		},
+		{
+			name:  "top 2",
+			topK:  2,
+			files: []string{"regions_before.json", "regions_after.json"},
+			want: map[string]float64{
+				`hbase_region_read_request_rate{host="rs1.example.com",hregion="0a5e8f3c",htable="orders",namespace="default",role="regionserver"}`:             100,
+				`hbase_region_write_request_rate{host="rs1.example.com",hregion="0a5e8f3c",htable="orders",namespace="default",role="regionserver"}`:            0,
+				`hbase_region_read_request_rate{host="rs1.example.com",hregion="1b6f9a4d",htable="orders",namespace="default",role="regionserver"}`:             0,
+				`hbase_region_write_request_rate{host="rs1.example.com",hregion="1b6f9a4d",htable="orders",namespace="default",role="regionserver"}`:            20,
+				`hbase_region_hotspot_score{host="rs1.example.com",hregion="0a5e8f3c",htable="orders",namespace="default",role="regionserver"}`:                 100.0 / 120,
+				`hbase_region_hotspot_score{host="rs1.example.com",hregion="1b6f9a4d",htable="orders",namespace="default",role="regionserver"}`:                 20.0 / 120,
+				`hbase_region_hottest_request_rate{host="rs1.example.com",hregion="0a5e8f3c",htable="orders",namespace="default",rank="1",role="regionserver"}`: 100,
+				`hbase_region_hottest_request_rate{host="rs1.example.com",hregion="1b6f9a4d",htable="orders",namespace="default",rank="2",role="regionserver"}`: 20,
+			},
+			absent: []string{
+				`hbase_region_read_request_rate{host="rs1.example.com",hregion="5fad3e8b",`,
+				`hbase_region_read_request_rate{host="rs1.example.com",hregion="6abe4f9c",`,
+				`hbase_region_hotspot_score{host="rs1.example.com",hregion="5fad3e8b",`,
+			},
+		},
+		{
+			name:  "top 1",
+			topK:  1,
+			files: []string{"regions_before.json", "regions_after.json"},
+			want: map[string]float64{
+				`hbase_region_hottest_request_rate{host="rs1.example.com",hregion="0a5e8f3c",htable="orders",namespace="default",rank="1",role="regionserver"}`: 100,
+			},
+			absent: []string{
+				`hbase_region_hottest_request_rate{host="rs1.example.com",hregion="1b6f9a4d",`,
+			},
+		},
+		{
+			// The second scrape of regions_after.json saw no requests.
+			name:  "idle",
+			topK:  2,
+			files: []string{"regions_before.json", "regions_after.json", "regions_after.json"},
+			want: map[string]float64{
+				`hbase_region_read_request_rate{host="rs1.example.com",hregion="0a5e8f3c",htable="orders",namespace="default",role="regionserver"}`: 0,
+				`hbase_region_hotspot_score{host="rs1.example.com",hregion="0a5e8f3c",htable="orders",namespace="default",role="regionserver"}`:     0,
+				`hbase_region_hotspot_score{host="rs1.example.com",hregion="5fad3e8b",htable="users",namespace="default",role="regionserver"}`:      0,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewRsRegion(log.NewNopLogger(), nil, RsRegionOptions{
+				Hotspots:    true,
+				HotspotTopK: test.topK,
+			})
+
+			got := scrapeRegionFixtures(t, r, test.files...)
+			checkValues(t, got, test.want, test.absent...)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type rsRegionLifecycleMetrics struct {
+	opened *prometheus.CounterVec
+	closed *prometheus.CounterVec
+	age    *prometheus.Desc
+}
+
+func newRsRegionLifecycleMetrics(labels []string) *rsRegionLifecycleMetrics {
+	subsystem := "region"
+
+	return &rsRegionLifecycleMetrics{
+		opened: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "opened_total"),
+			Help: "The number of regions that appeared on the regionserver since the exporter started.",
+		}, []string{"host", "role", "namespace", "htable"}),
+		closed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "closed_total"),
+			Help: "The number of regions that disappeared from the regionserver since the exporter started.",
+		}, []string{"host", "role", "namespace", "htable"}),
+		age: newMetric("age_seconds", "The time since the exporter first saw the region on the regionserver in seconds.", labels),
+	}
+}
+
+func (l *rsRegionLifecycleMetrics) describe(ch chan<- *prometheus.Desc) {
+	l.opened.Describe(ch)
+	l.closed.Describe(ch)
+	ch <- l.age
+}
+
+// collectLifecycle diffs the regions of this scrape against the previous
+// one. The first scrape only records the regions, so an exporter restart
+// does not count every region as opened.
+func (r *RsRegion) collectLifecycle(ch chan<- prometheus.Metric, host, role string, regions map[string]*hbaseRegion, now time.Time) {
+	firstSeen := make(map[string]time.Time, len(regions))
+	var opened, closed []*hbaseRegion
+
+	for key, region := range regions {
+		seen, ok := r.firstSeen[key]
+		if !ok {
+			seen = now
+			if r.lifecycleStarted {
+				opened = append(opened, region)
+			}
+		}
+		firstSeen[key] = seen
+	}
+	for key, region := range r.lastRegions {
+		if _, ok := regions[key]; !ok {
+			closed = append(closed, region)
+		}
+	}
+	r.firstSeen = firstSeen
+	r.lastRegions = regions
+	r.lifecycleStarted = true
+
+	for _, region := range opened {
+		r.lifecycle.opened.WithLabelValues(host, role, region.Namespace, region.Table).Inc()
+	}
+	for _, region := range closed {
+		r.lifecycle.closed.WithLabelValues(host, role, region.Namespace, region.Table).Inc()
+	}
+
+	if r.opts.LogRegionChanges {
+		r.logRegionChanges(host, opened, closed)
+	}
+
+	for key, region := range regions {
+		ch <- prometheus.MustNewConstMetric(
+			r.lifecycle.age,
+			prometheus.GaugeValue,
+			now.Sub(firstSeen[key]).Seconds(),
+			r.regionLabels(host, role, region)...,
+		)
+	}
+
+	r.lifecycle.opened.Collect(ch)
+	r.lifecycle.closed.Collect(ch)
+}
+
+// logRegionChanges logs one line per region that was opened or closed. A
+// region closing while two or more regions of its table open in the same
+// scrape is logged as a split instead.
+func (r *RsRegion) logRegionChanges(host string, opened, closed []*hbaseRegion) {
+	openedByTable := map[string][]*hbaseRegion{}
+	for _, region := range opened {
+		table := region.Namespace + ":" + region.Table
+		openedByTable[table] = append(openedByTable[table], region)
+	}
+
+	for _, region := range closed {
+		table := region.Namespace + ":" + region.Table
+		daughters := openedByTable[table]
+		if len(daughters) < 2 {
+			_ = level.Info(r.logger).Log(
+				"msg", "region closed",
+				"host", host,
+				"namespace", region.Namespace,
+				"htable", region.Table,
+				"hregion", region.Region,
+			)
+			continue
+		}
+
+		names := make([]string, 0, len(daughters))
+		for _, daughter := range daughters {
+			names = append(names, daughter.Region)
+		}
+		sort.Strings(names)
+		delete(openedByTable, table)
+
+		_ = level.Info(r.logger).Log(
+			"msg", "region split",
+			"host", host,
+			"namespace", region.Namespace,
+			"htable", region.Table,
+			"hregion", region.Region,
+			"daughters", strings.Join(names, ","),
+		)
+	}
+
+	for _, regions := range openedByTable {
+		for _, region := range regions {
+			_ = level.Info(r.logger).Log(
+				"msg", "region opened",
+				"host", host,
+				"namespace", region.Namespace,
+				"htable", region.Table,
+				"hregion", region.Region,
+			)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestRsRegionLifecycle(t *testing.T) {
+	// Between the fixtures, region 2c7a0b5e of default:orders split into
+	// 3d8b1c6f and 4e9c2d7a, 6abe4f9c of default:users and 8cda6b1e of
+	// analytics:empty opened and 7bcf5a0d of analytics:events closed.
+	for _, test := range []struct {
+		name   string
+		files  []string
+		want   map[string]float64
+		absent []string
+		logs   []string
+	}{
+		{
+			name:  "first scrape",
+			files: []string{"regions_before.json"},
+			want: map[string]float64{
+				`hbase_region_age_seconds{host="rs1.example.com",hregion="0a5e8f3c",htable="orders",namespace="default",role="regionserver"}`: 0,
+			},
+			// The regions of the first scrape are not counted as opened.
+			absent: []string{"hbase_region_opened_total", "hbase_region_closed_total"},
+		},
+		{
+			name:  "changes",
+			files: []string{"regions_before.json", "regions_after.json"},
+			want: map[string]float64{
+				`hbase_region_opened_total{host="rs1.example.com",htable="orders",namespace="default",role="regionserver"}`:                   2,
+				`hbase_region_opened_total{host="rs1.example.com",htable="users",namespace="default",role="regionserver"}`:                    1,
+				`hbase_region_opened_total{host="rs1.example.com",htable="empty",namespace="analytics",role="regionserver"}`:                  1,
+				`hbase_region_closed_total{host="rs1.example.com",htable="orders",namespace="default",role="regionserver"}`:                   1,
+				`hbase_region_closed_total{host="rs1.example.com",htable="events",namespace="analytics",role="regionserver"}`:                 1,
+				`hbase_region_age_seconds{host="rs1.example.com",hregion="0a5e8f3c",htable="orders",namespace="default",role="regionserver"}`: 10,
+				`hbase_region_age_seconds{host="rs1.example.com",hregion="6abe4f9c",htable="users",namespace="default",role="regionserver"}`:  0,
+			},
+			absent: []string{
+				`hbase_region_closed_total{host="rs1.example.com",htable="users",`,
+				`hbase_region_age_seconds{host="rs1.example.com",hregion="2c7a0b5e",`,
+				`hbase_region_age_seconds{host="rs1.example.com",hregion="7bcf5a0d",`,
+			},
+			logs: []string{
+				`msg="region split" host=rs1.example.com namespace=default htable=orders hregion=2c7a0b5e daughters=3d8b1c6f,4e9c2d7a`,
+				`msg="region opened" host=rs1.example.com namespace=default htable=users hregion=6abe4f9c`,
+				`msg="region opened" host=rs1.example.com namespace=analytics htable=empty hregion=8cda6b1e`,
+				`msg="region closed" host=rs1.example.com namespace=analytics htable=events hregion=7bcf5a0d`,
+			},
+		},
+		{
+			// Counters keep their value while nothing changes, and the
+			// changes are only logged by the second scrape.
+			name:  "no changes",
+			files: []string{"regions_before.json", "regions_after.json", "regions_after.json"},
+			want: map[string]float64{
+				`hbase_region_opened_total{host="rs1.example.com",htable="orders",namespace="default",role="regionserver"}`:                   2,
+				`hbase_region_closed_total{host="rs1.example.com",htable="orders",namespace="default",role="regionserver"}`:                   1,
+				`hbase_region_age_seconds{host="rs1.example.com",hregion="0a5e8f3c",htable="orders",namespace="default",role="regionserver"}`: 20,
+				`hbase_region_age_seconds{host="rs1.example.com",hregion="6abe4f9c",htable="users",namespace="default",role="regionserver"}`:  10,
+			},
+			logs: []string{
+				`msg="region split" host=rs1.example.com namespace=default htable=orders hregion=2c7a0b5e daughters=3d8b1c6f,4e9c2d7a`,
+				`msg="region opened" host=rs1.example.com namespace=default htable=users hregion=6abe4f9c`,
+				`msg="region opened" host=rs1.example.com namespace=analytics htable=empty hregion=8cda6b1e`,
+				`msg="region closed" host=rs1.example.com namespace=analytics htable=events hregion=7bcf5a0d`,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := NewRsRegion(log.NewLogfmtLogger(&buf), nil, RsRegionOptions{
+				Lifecycle:        true,
+				LogRegionChanges: true,
+			})
+
+			got := scrapeRegionFixtures(t, r, test.files...)
+			checkValues(t, got, test.want, test.absent...)
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if buf.Len() == 0 {
+				lines = nil
+			}
+			if len(lines) != len(test.logs) {
+				t.Errorf("got %d log lines, want %d:\n%s", len(lines), len(test.logs), buf.String())
+			}
+			for _, want := range test.logs {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("missing log line %s in:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
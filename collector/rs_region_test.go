@@ -0,0 +1,183 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// rsRegionState exports the hotspot and lifecycle metrics of the regions of
+// one fetch as collected at now, so that rates and ages do not depend on
+// the time the test takes.
+type rsRegionState struct {
+	r          *RsRegion
+	host, role string
+	regions    map[string]*hbaseRegion
+	now        time.Time
+}
+
+func (s *rsRegionState) Describe(ch chan<- *prometheus.Desc) {
+	if s.r.opts.Hotspots {
+		s.r.hotspots.describe(ch)
+	}
+	if s.r.opts.Lifecycle {
+		s.r.lifecycle.describe(ch)
+	}
+}
+
+func (s *rsRegionState) Collect(ch chan<- prometheus.Metric) {
+	if s.r.opts.Hotspots {
+		s.r.collectHotspots(ch, s.host, s.role, s.regions, s.now)
+	}
+	if s.r.opts.Lifecycle {
+		s.r.collectLifecycle(ch, s.host, s.role, s.regions, s.now)
+	}
+}
+
+// scrapeRegionFixtures feeds the sub=Regions testdata files to the hotspot
+// and lifecycle state of r in turn, 10 seconds apart, and returns the
+// values gathered from the last.
+func scrapeRegionFixtures(t *testing.T, r *RsRegion, files ...string) map[string]float64 {
+	start := time.Unix(1600000000, 0)
+
+	var got map[string]float64
+	for i, file := range files {
+		r.jmx = newJmxFixtureClient(t, map[string]string{regionsQry: file})
+		host, role, regions, err := r.fetchAndDecodeRsRegion()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = gatherValues(t, &rsRegionState{
+			r:       r,
+			host:    host,
+			role:    strings.ToLower(role),
+			regions: regions,
+			now:     start.Add(time.Duration(i) * 10 * time.Second),
+		})
+	}
+	return got
+}
+
+func TestRsRegionIgnoresOverlappedFetch(t *testing.T) {
+	// The first fetch is answered last, with a region that has closed
+	// since; the second fetch answers with the regions of the fixture.
+	fixture := readRegionsFixture(t)
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"beans":[{"tag.Context":"regionserver","tag.Hostname":"rs1.example.com",` +
+				`"Namespace_default_table_closed_region_0123_metric_readRequestCount":1}]}`))
+			return
+		}
+		_, _ = w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL + "/jmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRsRegion(log.NewNopLogger(), NewJmxClient(u, 0, 0), RsRegionOptions{
+		Hotspots:    true,
+		HotspotTopK: 1,
+		Lifecycle:   true,
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		collectMetrics(r)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	collectMetrics(r)
+	wg.Wait()
+
+	if len(r.lastRegions) != 5 {
+		t.Errorf("got %d regions in the lifecycle state, want the 5 of the later fetch", len(r.lastRegions))
+	}
+	if _, ok := r.samples["default,closed,0123"]; ok {
+		t.Error("the earlier fetch overwrote the hotspot samples")
+	}
+}
+
+func TestRsRegionOldestMajorCompaction(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		fixture string
+		want    map[string]float64
+		absent  []string
+	}{
+		{
+			// Region 6abe4f9c of default:users publishes no
+			// lastMajorCompactionAge and is left out.
+			name:    "regions",
+			fixture: "regions_after.json",
+			want: map[string]float64{
+				`hbase_region_last_major_compaction_age_ms{host="rs1.example.com",hregion="1b6f9a4d",htable="orders",namespace="default",role="regionserver"}`: 86400000,
+				`hbase_table_oldest_major_compaction_age_ms{host="rs1.example.com",htable="orders",namespace="default",role="regionserver"}`:                   86400000,
+				`hbase_table_oldest_major_compaction_age_ms{host="rs1.example.com",htable="users",namespace="default",role="regionserver"}`:                    7200000,
+				`hbase_table_oldest_major_compaction_age_ms{host="rs1.example.com",htable="empty",namespace="analytics",role="regionserver"}`:                  0,
+			},
+		},
+		{
+			// HBase 1.x regions publish no lastMajorCompactionAge.
+			name:    "no compaction ages",
+			fixture: "regions_before.json",
+			absent:  []string{"hbase_table_oldest_major_compaction_age_ms", "hbase_region_last_major_compaction_age_ms"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			jmx := newJmxFixtureClient(t, map[string]string{regionsQry: test.fixture})
+
+			got := gatherValues(t, NewRsRegion(log.NewNopLogger(), jmx, RsRegionOptions{}))
+			checkValues(t, got, test.want, test.absent...)
+		})
+	}
+}
+
+func TestRsRegionLocality(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		fixture string
+		want    map[string]float64
+		absent  []string
+	}{
+		{
+			// default:orders holds 3000 bytes fully local and 1000 half
+			// local; region 6abe4f9c of default:users publishes no
+			// dataLocality and is left out; analytics:empty has no store
+			// files yet.
+			name:    "regions",
+			fixture: "regions_after.json",
+			want: map[string]float64{
+				`hbase_region_data_locality{host="rs1.example.com",hregion="1b6f9a4d",htable="orders",namespace="default",role="regionserver"}`: 0.5,
+				`hbase_table_locality{host="rs1.example.com",htable="orders",namespace="default",role="regionserver"}`:                          0.875,
+				`hbase_table_locality{host="rs1.example.com",htable="users",namespace="default",role="regionserver"}`:                           0.25,
+				`hbase_table_locality{host="rs1.example.com",htable="empty",namespace="analytics",role="regionserver"}`:                         1,
+			},
+		},
+		{
+			name:    "no locality",
+			fixture: "regions_before.json",
+			absent:  []string{"hbase_table_locality", "hbase_region_data_locality"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			jmx := newJmxFixtureClient(t, map[string]string{regionsQry: test.fixture})
+
+			got := gatherValues(t, NewRsRegion(log.NewNopLogger(), jmx, RsRegionOptions{}))
+			checkValues(t, got, test.want, test.absent...)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseRsReplicationLabels       = []string{"host", "role"}
+	defaultHBaseRsReplicationSourceLabels = []string{"host", "role", "peer_id"}
+)
+
+type rsReplicationMetric struct {
+	Type prometheus.ValueType
+	Desc *prometheus.Desc
+}
+
+// RsReplication collects the replication source and sink metrics of a
+// regionserver. Source attributes are published per peer as
+// source.<peerId>.<metric>.
+type RsReplication struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	sources map[string]*rsReplicationMetric
+	sinks   map[string]*rsReplicationMetric
+}
+
+func newRsReplicationMetric(valueType prometheus.ValueType, metric, doc string, labels []string) *rsReplicationMetric {
+	subsystem := "replication"
+
+	return &rsReplicationMetric{
+		Type: valueType,
+		Desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, metric),
+			doc,
+			labels, nil,
+		),
+	}
+}
+
+func NewRsReplication(logger log.Logger, jmx *JmxClient) *RsReplication {
+	subsystem := "replication"
+
+	return &RsReplication{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase replication endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase replication scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		sources: map[string]*rsReplicationMetric{
+			"ageOfLastShippedOp": newRsReplicationMetric(prometheus.GaugeValue, "source_age_of_last_shipped_op_ms",
+				"The age in milliseconds of the last edit shipped to the peer.", defaultHBaseRsReplicationSourceLabels),
+			"sizeOfLogQueue": newRsReplicationMetric(prometheus.GaugeValue, "source_size_of_log_queue",
+				"The number of WALs waiting to be replicated to the peer.", defaultHBaseRsReplicationSourceLabels),
+			"replicationLag": newRsReplicationMetric(prometheus.GaugeValue, "source_replication_lag_ms",
+				"The replication lag to the peer in milliseconds.", defaultHBaseRsReplicationSourceLabels),
+			"shippedBytes": newRsReplicationMetric(prometheus.CounterValue, "source_shipped_bytes",
+				"The number of bytes shipped to the peer.", defaultHBaseRsReplicationSourceLabels),
+			"shippedOps": newRsReplicationMetric(prometheus.CounterValue, "source_shipped_ops",
+				"The number of edits shipped to the peer.", defaultHBaseRsReplicationSourceLabels),
+			"shippedBatches": newRsReplicationMetric(prometheus.CounterValue, "source_shipped_batches",
+				"The number of batches shipped to the peer.", defaultHBaseRsReplicationSourceLabels),
+			"logEditsFiltered": newRsReplicationMetric(prometheus.CounterValue, "source_log_edits_filtered",
+				"The number of WAL edits filtered out before shipping to the peer.", defaultHBaseRsReplicationSourceLabels),
+			"logEditsRead": newRsReplicationMetric(prometheus.CounterValue, "source_log_edits_read",
+				"The number of WAL edits read for the peer.", defaultHBaseRsReplicationSourceLabels),
+		},
+
+		sinks: map[string]*rsReplicationMetric{
+			"ageOfLastAppliedOp": newRsReplicationMetric(prometheus.GaugeValue, "sink_age_of_last_applied_op_ms",
+				"The age in milliseconds of the last edit applied by the sink.", defaultHBaseRsReplicationLabels),
+			"appliedOps": newRsReplicationMetric(prometheus.CounterValue, "sink_applied_ops",
+				"The number of edits applied by the sink.", defaultHBaseRsReplicationLabels),
+			"appliedBatches": newRsReplicationMetric(prometheus.CounterValue, "sink_applied_batches",
+				"The number of batches applied by the sink.", defaultHBaseRsReplicationLabels),
+		},
+	}
+}
+
+func (r *RsReplication) upGauge() prometheus.Gauge {
+	return r.up
+}
+
+func (r *RsReplication) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range r.sources {
+		ch <- metric.Desc
+	}
+	for _, metric := range r.sinks {
+		ch <- metric.Desc
+	}
+
+	ch <- r.up.Desc()
+	ch <- r.totalScrapes.Desc()
+	ch <- r.jsonParseFailures.Desc()
+}
+
+func (r *RsReplication) Collect(ch chan<- prometheus.Metric) {
+	r.totalScrapes.Inc()
+	defer func() {
+		ch <- r.up
+		ch <- r.totalScrapes
+		ch <- r.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(r.logger, r.jmx, "Hadoop:service=HBase,name=RegionServer,sub=Replication")
+	if err != nil {
+		if isJmxReadError(err) {
+			r.jsonParseFailures.Inc()
+		}
+		r.up.Set(0)
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to fetch replication metrics",
+			"err", err,
+		)
+		return
+	}
+
+	bean, err := firstBean(bts)
+	if err != nil {
+		r.up.Set(0)
+		r.jsonParseFailures.Inc()
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to decode replication metrics",
+			"err", err,
+		)
+		return
+	}
+	r.up.Set(1)
+
+	data := bean.Map()
+
+	host := data["tag.Hostname"].String()
+	role := strings.ToLower(data["tag.Context"].String())
+
+	for k, v := range data {
+		switch {
+		case strings.HasPrefix(k, "source."):
+			// Attributes without a peer, e.g. source.shippedBytes, are the
+			// sum over all peers and are skipped.
+			i := strings.LastIndex(k, ".")
+			if i <= len("source.") {
+				continue
+			}
+
+			metric, ok := r.sources[k[i+1:]]
+			if !ok {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				v.Float(),
+				host, role, k[len("source."):i],
+			)
+		case strings.HasPrefix(k, "sink."):
+			metric, ok := r.sinks[strings.TrimPrefix(k, "sink.")]
+			if !ok {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				v.Float(),
+				host, role,
+			)
+		}
+	}
+}
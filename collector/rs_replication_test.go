@@ -0,0 +1,85 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestRsReplication(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		fixture string
+		want    map[string]float64
+		absent  []string
+		sources int
+	}{
+		{
+			name:    "sources and sink",
+			fixture: "replication.json",
+			want: map[string]float64{
+				`hbase_replication_up{}`: 1,
+				`hbase_replication_source_shipped_bytes{host="rs1.example.com",peer_id="1",role="regionserver"}`:                                   4096,
+				`hbase_replication_source_shipped_ops{host="rs1.example.com",peer_id="1",role="regionserver"}`:                                     200,
+				`hbase_replication_source_shipped_batches{host="rs1.example.com",peer_id="1",role="regionserver"}`:                                 20,
+				`hbase_replication_source_age_of_last_shipped_op_ms{host="rs1.example.com",peer_id="1",role="regionserver"}`:                       900,
+				`hbase_replication_source_size_of_log_queue{host="rs1.example.com",peer_id="1",role="regionserver"}`:                               1,
+				`hbase_replication_source_replication_lag_ms{host="rs1.example.com",peer_id="1",role="regionserver"}`:                              1200,
+				`hbase_replication_source_log_edits_read{host="rs1.example.com",peer_id="1",role="regionserver"}`:                                  250,
+				`hbase_replication_source_log_edits_filtered{host="rs1.example.com",peer_id="1",role="regionserver"}`:                              50,
+				`hbase_replication_source_shipped_bytes{host="rs1.example.com",peer_id="dc2.backup",role="regionserver"}`:                          3072,
+				`hbase_replication_source_shipped_ops{host="rs1.example.com",peer_id="dc2.backup",role="regionserver"}`:                            100,
+				`hbase_replication_source_size_of_log_queue{host="rs1.example.com",peer_id="dc2.backup",role="regionserver"}`:                      2,
+				`hbase_replication_source_shipped_ops{host="rs1.example.com",peer_id="1-rs2.example.com,16020,1600000000000",role="regionserver"}`: 7,
+				`hbase_replication_sink_age_of_last_applied_op_ms{host="rs1.example.com",role="regionserver"}`:                                     150,
+				`hbase_replication_sink_applied_ops{host="rs1.example.com",role="regionserver"}`:                                                   80,
+				`hbase_replication_sink_applied_batches{host="rs1.example.com",role="regionserver"}`:                                               8,
+			},
+			// The peerless source.<metric> aggregates are not a peer and
+			// unknown metrics are skipped, leaving 8+3+1 source samples.
+			absent: []string{
+				`hbase_replication_source_shipped_bytes{host="rs1.example.com",peer_id="",`,
+			},
+			sources: 12,
+		},
+		{
+			name:    "sink only",
+			fixture: "replication_sink.json",
+			want: map[string]float64{
+				`hbase_replication_up{}`: 1,
+				`hbase_replication_sink_applied_ops{host="rs2.example.com",role="regionserver"}`:     12,
+				`hbase_replication_sink_applied_batches{host="rs2.example.com",role="regionserver"}`: 2,
+			},
+			absent: []string{"hbase_replication_source_"},
+		},
+		{
+			name: "no bean",
+			want: map[string]float64{
+				`hbase_replication_up{}`: 0,
+			},
+			absent: []string{"hbase_replication_source_", "hbase_replication_sink_"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fixtures := map[string]string{}
+			if test.fixture != "" {
+				fixtures["Hadoop:service=HBase,name=RegionServer,sub=Replication"] = test.fixture
+			}
+			jmx := newJmxFixtureClient(t, fixtures)
+
+			got := gatherValues(t, NewRsReplication(log.NewNopLogger(), jmx))
+			checkValues(t, got, test.want, test.absent...)
+
+			sources := 0
+			for key := range got {
+				if strings.HasPrefix(key, "hbase_replication_source_") {
+					sources++
+				}
+			}
+			if sources != test.sources {
+				t.Errorf("got %d source samples, want %d", sources, test.sources)
+			}
+		})
+	}
+}
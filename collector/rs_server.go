@@ -2,10 +2,6 @@ package collector
 
 import (
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
 	"strings"
 
 	"github.com/go-kit/kit/log"
@@ -31,22 +27,35 @@ type rsServerMetric struct {
 	Labels func(rsServer rsServerResponse) []string
 }
 
+// rsServerBeanCollector is implemented by the regionserver collectors
+// exporting attributes of the sub=Server bean. RsServer fetches the bean,
+// the largest of a regionserver, once per scrape and hands it to each of
+// them along with the error of the fetch, if any.
+type rsServerBeanCollector interface {
+	Describe(ch chan<- *prometheus.Desc)
+	collectServerBean(ch chan<- prometheus.Metric, bean gjson.Result, err error)
+}
+
 type RsServer struct {
 	logger log.Logger
-	url    *url.URL
+	jmx    *JmxClient
 
 	up                              prometheus.Gauge
 	totalScrapes, jsonParseFailures prometheus.Counter
 
 	metrics []*rsServerMetric
+	parts   []rsServerBeanCollector
 }
 
-func NewRsServer(logger log.Logger, url *url.URL) *RsServer {
+// NewRsServer returns a collector for the sub=Server bean of a regionserver
+// that also collects parts, e.g. RsCache, from the same fetch.
+func NewRsServer(logger log.Logger, jmx *JmxClient, parts ...rsServerBeanCollector) *RsServer {
 	subsystem := "server"
 
 	return &RsServer{
 		logger: logger,
-		url:    url,
+		jmx:    jmx,
+		parts:  parts,
 
 		up: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
@@ -242,61 +251,74 @@ func NewRsServer(logger log.Logger, url *url.URL) *RsServer {
 				},
 				Labels: defaultHBaseRsServerLabelServerValues,
 			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "percent_files_local"),
+					"The percentage of store file data that is local to the regionserver.",
+					defaultHBaseRsServerLabels, nil,
+				),
+				Value: func(rsServer rsServerResponse) float64 {
+					return rsServer.PercentFilesLocal
+				},
+				Labels: defaultHBaseRsServerLabelServerValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "percent_files_local_secondary_regions"),
+					"The percentage of store file data of secondary region replicas that is local to the regionserver.",
+					defaultHBaseRsServerLabels, nil,
+				),
+				Value: func(rsServer rsServerResponse) float64 {
+					return rsServer.PercentFilesLocalSecondaryRegions
+				},
+				Labels: defaultHBaseRsServerLabelServerValues,
+			},
 		},
 	}
 }
 
+func (m *RsServer) upGauge() prometheus.Gauge {
+	return m.up
+}
+
 func (m *RsServer) Describe(ch chan<- *prometheus.Desc) {
 	for _, metric := range m.metrics {
 		ch <- metric.Desc
 	}
+	for _, part := range m.parts {
+		part.Describe(ch)
+	}
 
 	ch <- m.up.Desc()
 	ch <- m.totalScrapes.Desc()
 	ch <- m.jsonParseFailures.Desc()
 }
 
-func (r *RsServer) fetchAndDecodeRsServer() (rsServerResponse, error) {
+func (r *RsServer) fetchAndDecodeRsServer() (gjson.Result, rsServerResponse, error) {
 	var rsr rsServerResponse
 
-	u := *r.url
-	url := u.String() + "?" + "qry=Hadoop:service=HBase,name=RegionServer,sub=Server"
-	res, err := http.Get(url)
-
+	bts, err := fetchJmx(r.logger, r.jmx, "Hadoop:service=HBase,name=RegionServer,sub=Server")
 	if err != nil {
-		return rsr, fmt.Errorf("failed to get cluster health from %s://%s:%s%s: %s",
-			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
-	}
-
-	defer func() {
-		err = res.Body.Close()
-		if err != nil {
-			_ = level.Warn(r.logger).Log(
-				"msg", "failed to close http.Client",
-				"err", err,
-			)
+		if isJmxReadError(err) {
+			r.jsonParseFailures.Inc()
 		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return rsr, fmt.Errorf("HTTP Request failed with code %d", res.StatusCode)
+		return gjson.Result{}, rsr, err
 	}
 
-	bts, err := ioutil.ReadAll(res.Body)
+	bean, err := firstBean(bts)
 	if err != nil {
 		r.jsonParseFailures.Inc()
-		return rsr, err
+		return gjson.Result{}, rsr, err
 	}
 
-	data := gjson.Get(string(bts), "beans")
-	rsServerStr := data.Array()[0].String()
-
-	if err := json.Unmarshal([]byte(rsServerStr), &rsr); err != nil {
+	if err := json.Unmarshal([]byte(bean.Raw), &rsr); err != nil {
 		r.jsonParseFailures.Inc()
-		return rsr, err
+		return gjson.Result{}, rsr, err
 	}
 
-	return rsr, nil
+	return bean, rsr, nil
 
 }
 
@@ -310,7 +332,11 @@ func (r *RsServer) Collect(ch chan<- prometheus.Metric) {
 		ch <- r.jsonParseFailures
 	}()
 
-	rsServerResp, err := r.fetchAndDecodeRsServer()
+	bean, rsServerResp, err := r.fetchAndDecodeRsServer()
+
+	for _, part := range r.parts {
+		part.collectServerBean(ch, bean, err)
+	}
 
 	if err != nil {
 		r.up.Set(0)
@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestRsServer(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		fixture string
+		want    map[string]float64
+		absent  []string
+	}{
+		{
+			name:    "server bean",
+			fixture: "regionserver_server.json",
+			want: map[string]float64{
+				`hbase_server_up{}`: 1,
+				`hbase_server_region_count{host="rs1.example.com",role="regionserver"}`:                          7,
+				`hbase_server_percent_files_local{host="rs1.example.com",role="regionserver"}`:                   87.5,
+				`hbase_server_percent_files_local_secondary_regions{host="rs1.example.com",role="regionserver"}`: 40,
+			},
+		},
+		{
+			name: "no server bean",
+			want: map[string]float64{
+				`hbase_server_up{}`: 0,
+			},
+			absent: []string{"hbase_server_percent_files_local"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fixtures := map[string]string{}
+			if test.fixture != "" {
+				fixtures["Hadoop:service=HBase,name=RegionServer,sub=Server"] = test.fixture
+			}
+			jmx := newJmxFixtureClient(t, fixtures)
+
+			got := gatherValues(t, NewRsServer(log.NewNopLogger(), jmx))
+			checkValues(t, got, test.want, test.absent...)
+		})
+	}
+}
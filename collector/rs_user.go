@@ -0,0 +1,245 @@
+package collector
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tidwall/gjson"
+)
+
+var defaultHBaseRsUserLabels = []string{"host", "role", "user"}
+
+type rsUserMetric struct {
+	Type prometheus.ValueType
+	Desc *prometheus.Desc
+}
+
+// RsUserOptions bounds the users exported by RsUser. A user is exported when
+// it matches Allowlist, if set, and does not match Denylist, if set.
+type RsUserOptions struct {
+	Allowlist *regexp.Regexp
+	Denylist  *regexp.Regexp
+}
+
+// hbaseUser holds the attributes of one user of the Users bean. Counters only
+// published per client, user_<name>_client_<host>_metric_<m>, are summed into
+// the user.
+type hbaseUser struct {
+	Attrs        map[string]gjson.Result
+	Counts       map[*rsUserMetric]float64
+	ClientCounts map[*rsUserMetric]float64
+}
+
+// RsUser collects the per user request metrics of a regionserver, published
+// as user_<name>_metric_<m> when hbase.regionserver.user.metrics.enabled is
+// set.
+type RsUser struct {
+	logger log.Logger
+	jmx    *JmxClient
+	opts   RsUserOptions
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	metrics    map[string]*rsUserMetric
+	histograms []*hbaseHistogram
+}
+
+func NewRsUser(logger log.Logger, jmx *JmxClient, opts RsUserOptions) *RsUser {
+	subsystem := "user"
+	newMetric := func(valueType prometheus.ValueType, name, help string) *rsUserMetric {
+		return &rsUserMetric{
+			Type: valueType,
+			Desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, name),
+				help,
+				defaultHBaseRsUserLabels, nil,
+			),
+		}
+	}
+	newHistogram := func(attr, name, help string) *hbaseHistogram {
+		return &hbaseHistogram{
+			Attr: attr,
+			Desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, subsystem, name),
+				help,
+				defaultHBaseRsUserLabels, nil,
+			),
+		}
+	}
+	readRequests := newMetric(prometheus.CounterValue, "read_request_count", "The number of read requests of the user.")
+	writeRequests := newMetric(prometheus.CounterValue, "write_request_count", "The number of write requests of the user.")
+	filteredReadRequests := newMetric(prometheus.CounterValue, "filtered_read_request_count", "The number of read requests of the user filtered out by the server.")
+
+	return &RsUser{
+		logger: logger,
+		jmx:    jmx,
+		opts:   opts,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase user endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase user scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		// HBase versions disagree on the plural, so both spellings feed the
+		// same metric.
+		metrics: map[string]*rsUserMetric{
+			"readRequestCount":          readRequests,
+			"readRequestsCount":         readRequests,
+			"writeRequestCount":         writeRequests,
+			"writeRequestsCount":        writeRequests,
+			"filteredReadRequestCount":  filteredReadRequests,
+			"filteredReadRequestsCount": filteredReadRequests,
+		},
+
+		histograms: []*hbaseHistogram{
+			newHistogram("get", "get_time_ms", "The time of gets of the user in milliseconds."),
+			newHistogram("scanTime", "scan_time_ms", "The time of scans of the user in milliseconds."),
+			newHistogram("mutate", "mutate_time_ms", "The time of puts of the user in milliseconds."),
+			newHistogram("delete", "delete_time_ms", "The time of deletes of the user in milliseconds."),
+			newHistogram("increment", "increment_time_ms", "The time of increments of the user in milliseconds."),
+			newHistogram("append", "append_time_ms", "The time of appends of the user in milliseconds."),
+			newHistogram("replay", "replay_time_ms", "The time of WAL edit replays of the user in milliseconds."),
+		},
+	}
+}
+
+func (r *RsUser) upGauge() prometheus.Gauge {
+	return r.up
+}
+
+func (r *RsUser) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range r.metrics {
+		ch <- metric.Desc
+	}
+	for _, histogram := range r.histograms {
+		ch <- histogram.Desc
+	}
+
+	ch <- r.up.Desc()
+	ch <- r.totalScrapes.Desc()
+	ch <- r.jsonParseFailures.Desc()
+}
+
+func (r *RsUser) Collect(ch chan<- prometheus.Metric) {
+	r.totalScrapes.Inc()
+	defer func() {
+		ch <- r.up
+		ch <- r.totalScrapes
+		ch <- r.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(r.logger, r.jmx, "Hadoop:service=HBase,name=RegionServer,sub=Users")
+	if err != nil {
+		if isJmxReadError(err) {
+			r.jsonParseFailures.Inc()
+		}
+		r.up.Set(0)
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to fetch user metrics",
+			"err", err,
+		)
+		return
+	}
+
+	bean, err := firstBean(bts)
+	if err != nil {
+		r.up.Set(0)
+		r.jsonParseFailures.Inc()
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to decode user metrics",
+			"err", err,
+		)
+		return
+	}
+	r.up.Set(1)
+
+	data := bean.Map()
+
+	host := data["tag.Hostname"].String()
+	role := strings.ToLower(data["tag.Context"].String())
+
+	users := map[string]*hbaseUser{}
+	for k, v := range data {
+		if !strings.HasPrefix(strings.ToLower(k), "user_") {
+			continue
+		}
+
+		// User names may contain underscores, so the metric starts after
+		// the last _metric_.
+		rest := k[len("user_"):]
+		i := strings.LastIndex(rest, "_metric_")
+		if i < 0 {
+			continue
+		}
+		name, attr := rest[:i], rest[i+len("_metric_"):]
+
+		client := false
+		if j := strings.Index(name, "_client_"); j >= 0 {
+			name, client = name[:j], true
+		}
+		if !r.exported(name) {
+			continue
+		}
+
+		user, ok := users[name]
+		if !ok {
+			user = &hbaseUser{
+				Attrs:        map[string]gjson.Result{},
+				Counts:       map[*rsUserMetric]float64{},
+				ClientCounts: map[*rsUserMetric]float64{},
+			}
+			users[name] = user
+		}
+
+		metric, ok := r.metrics[attr]
+		switch {
+		case client && ok:
+			user.ClientCounts[metric] += v.Float()
+		case ok:
+			user.Counts[metric] = v.Float()
+		case !client:
+			user.Attrs[attr] = v
+		}
+	}
+
+	for name, user := range users {
+		for metric, count := range user.ClientCounts {
+			if _, ok := user.Counts[metric]; !ok {
+				user.Counts[metric] = count
+			}
+		}
+		for metric, count := range user.Counts {
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				count,
+				host, role, name,
+			)
+		}
+
+		collectHistograms(ch, r.histograms, user.Attrs, host, role, name)
+	}
+}
+
+func (r *RsUser) exported(name string) bool {
+	if r.opts.Allowlist != nil && !r.opts.Allowlist.MatchString(name) {
+		return false
+	}
+	if r.opts.Denylist != nil && r.opts.Denylist.MatchString(name) {
+		return false
+	}
+
+	return true
+}
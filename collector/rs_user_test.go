@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestRsUser(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		fixture string
+		opts    RsUserOptions
+		want    map[string]float64
+		absent  []string
+	}{
+		{
+			name:    "all users",
+			fixture: "users.json",
+			want: map[string]float64{
+				`hbase_user_up{}`: 1,
+				// The user name svc_etl contains an underscore.
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="svc_etl"}`:  100,
+				`hbase_user_write_request_count{host="rs1.example.com",role="regionserver",user="svc_etl"}`: 20,
+				`hbase_user_get_time_ms_count{host="rs1.example.com",role="regionserver",user="svc_etl"}`:   50,
+				`hbase_user_get_time_ms_sum{host="rs1.example.com",role="regionserver",user="svc_etl"}`:     100,
+				// The read requests of alice are only published per client
+				// and are summed, writeRequestsCount is the plural spelling.
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="alice"}`:  12,
+				`hbase_user_write_request_count{host="rs1.example.com",role="regionserver",user="alice"}`: 3,
+				// The user count of bob wins over the sum of its clients.
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="bob"}`:            40,
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="hbase"}`:          1000,
+				`hbase_user_filtered_read_request_count{host="rs1.example.com",role="regionserver",user="hbase"}`: 10,
+			},
+			// Client histograms are not summed into the user.
+			absent: []string{
+				`hbase_user_get_time_ms_count{host="rs1.example.com",role="regionserver",user="alice"}`,
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="alice_client_`,
+			},
+		},
+		{
+			name:    "allowlist",
+			fixture: "users.json",
+			opts:    RsUserOptions{Allowlist: regexp.MustCompile("^(?:svc_.*|alice)$")},
+			want: map[string]float64{
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="svc_etl"}`: 100,
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="alice"}`:   12,
+			},
+			absent: []string{
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="bob"}`,
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="hbase"}`,
+			},
+		},
+		{
+			name:    "denylist",
+			fixture: "users.json",
+			opts:    RsUserOptions{Denylist: regexp.MustCompile("^(?:hbase)$")},
+			want: map[string]float64{
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="bob"}`: 40,
+			},
+			absent: []string{
+				`hbase_user_read_request_count{host="rs1.example.com",role="regionserver",user="hbase"}`,
+				`hbase_user_filtered_read_request_count{host="rs1.example.com",role="regionserver",user="hbase"}`,
+			},
+		},
+		{
+			name: "user metrics disabled",
+			want: map[string]float64{
+				`hbase_user_up{}`: 0,
+			},
+			absent: []string{"hbase_user_read_request_count"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fixtures := map[string]string{}
+			if test.fixture != "" {
+				fixtures["Hadoop:service=HBase,name=RegionServer,sub=Users"] = test.fixture
+			}
+			jmx := newJmxFixtureClient(t, fixtures)
+
+			got := gatherValues(t, NewRsUser(log.NewNopLogger(), jmx, test.opts))
+			checkValues(t, got, test.want, test.absent...)
+		})
+	}
+}
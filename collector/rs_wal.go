@@ -0,0 +1,229 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseRsWalLabels      = []string{"host", "role", "wal"}
+	defaultHBaseRsWalLabelValues = func(rsWal rsWalResponse) []string {
+		return []string{
+			rsWal.Host,
+			strings.ToLower(rsWal.Role),
+			beanProperty(rsWal.Name, "sub"),
+		}
+	}
+)
+
+type rsWalMetric struct {
+	Type   prometheus.ValueType
+	Desc   *prometheus.Desc
+	Value  func(rsWal rsWalResponse) float64
+	Labels func(rsWal rsWalResponse) []string
+}
+
+// RsWal collects the write-ahead log metrics of a regionserver.
+type RsWal struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	metrics    []*rsWalMetric
+	histograms []*hbaseHistogram
+}
+
+func NewRsWal(logger log.Logger, jmx *JmxClient) *RsWal {
+	subsystem := "wal"
+
+	return &RsWal{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase WAL endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase WAL scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		metrics: []*rsWalMetric{
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "append_count"),
+					"The number of appends to the write-ahead log.",
+					defaultHBaseRsWalLabels, nil,
+				),
+				Value: func(rsWal rsWalResponse) float64 {
+					return rsWal.AppendCount
+				},
+				Labels: defaultHBaseRsWalLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "slow_append_count"),
+					"The number of appends that took longer than 1s.",
+					defaultHBaseRsWalLabels, nil,
+				),
+				Value: func(rsWal rsWalResponse) float64 {
+					return rsWal.SlowAppendCount
+				},
+				Labels: defaultHBaseRsWalLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "roll_request"),
+					"The number of requests to roll the write-ahead log.",
+					defaultHBaseRsWalLabels, nil,
+				),
+				Value: func(rsWal rsWalResponse) float64 {
+					return rsWal.RollRequest
+				},
+				Labels: defaultHBaseRsWalLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "low_replica_roll_request"),
+					"The number of log rolls requested because of too few datanode replicas.",
+					defaultHBaseRsWalLabels, nil,
+				),
+				Value: func(rsWal rsWalResponse) float64 {
+					return rsWal.LowReplicaRollRequest
+				},
+				Labels: defaultHBaseRsWalLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "written_bytes"),
+					"The number of bytes written to the write-ahead log.",
+					defaultHBaseRsWalLabels, nil,
+				),
+				Value: func(rsWal rsWalResponse) float64 {
+					return rsWal.WrittenBytes
+				},
+				Labels: defaultHBaseRsWalLabelValues,
+			},
+		},
+
+		histograms: []*hbaseHistogram{
+			{
+				Attr: "SyncTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sync_time_ms"),
+					"The time it took to sync the write-ahead log to HDFS in milliseconds.",
+					defaultHBaseRsWalLabels, nil,
+				),
+			},
+			{
+				Attr: "AppendTime",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "append_time_ms"),
+					"The time an append to the write-ahead log took in milliseconds.",
+					defaultHBaseRsWalLabels, nil,
+				),
+			},
+			{
+				Attr: "AppendSize",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "append_size_bytes"),
+					"The size of appends to the write-ahead log in bytes.",
+					defaultHBaseRsWalLabels, nil,
+				),
+			},
+		},
+	}
+}
+
+func (r *RsWal) upGauge() prometheus.Gauge {
+	return r.up
+}
+
+func (r *RsWal) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range r.metrics {
+		ch <- metric.Desc
+	}
+	for _, histogram := range r.histograms {
+		ch <- histogram.Desc
+	}
+
+	ch <- r.up.Desc()
+	ch <- r.totalScrapes.Desc()
+	ch <- r.jsonParseFailures.Desc()
+}
+
+func (r *RsWal) Collect(ch chan<- prometheus.Metric) {
+	r.totalScrapes.Inc()
+	defer func() {
+		ch <- r.up
+		ch <- r.totalScrapes
+		ch <- r.jsonParseFailures
+	}()
+
+	// The pattern matches sub=WAL as well as the bean names the async
+	// WAL implementations of HBase 2.x register under.
+	bts, err := fetchJmx(r.logger, r.jmx, "Hadoop:service=HBase,name=RegionServer,sub=WAL*")
+	if err != nil {
+		if isJmxReadError(err) {
+			r.jsonParseFailures.Inc()
+		}
+		r.up.Set(0)
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to fetch wal metrics",
+			"err", err,
+		)
+		return
+	}
+
+	beans, err := allBeans(bts)
+	if err != nil {
+		r.up.Set(0)
+		r.jsonParseFailures.Inc()
+		_ = level.Warn(r.logger).Log(
+			"msg", "failed to decode wal metrics",
+			"err", err,
+		)
+		return
+	}
+	r.up.Set(1)
+
+	for _, bean := range beans {
+		var rsWalResp rsWalResponse
+		if err := json.Unmarshal([]byte(bean.Raw), &rsWalResp); err != nil {
+			r.jsonParseFailures.Inc()
+			_ = level.Warn(r.logger).Log(
+				"msg", "failed to decode wal metrics",
+				"bean", bean.Get("name").String(),
+				"err", err,
+			)
+			continue
+		}
+
+		for _, metric := range r.metrics {
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				metric.Value(rsWalResp),
+				metric.Labels(rsWalResp)...,
+			)
+		}
+
+		collectHistograms(ch, r.histograms, bean.Map(), defaultHBaseRsWalLabelValues(rsWalResp)...)
+	}
+}
@@ -0,0 +1,12 @@
+package collector
+
+type rsWalResponse struct {
+	Name                  string  `json:"name"`
+	Host                  string  `json:"tag.Hostname"`
+	Role                  string  `json:"tag.Context"`
+	AppendCount           float64 `json:"appendCount"`
+	SlowAppendCount       float64 `json:"slowAppendCount"`
+	RollRequest           float64 `json:"rollRequest"`
+	LowReplicaRollRequest float64 `json:"lowReplicaRollRequest"`
+	WrittenBytes          float64 `json:"writtenBytes"`
+}
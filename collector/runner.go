@@ -0,0 +1,208 @@
+package collector
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Runner collects its collectors concurrently, like node_exporter's
+// NodeCollector, with at most concurrency collections running against the
+// JMX endpoint at once. A collector that does not get a slot and finish
+// within its budget is reported as failed and its metrics are dropped from
+// the scrape, so one slow bean does not fail the whole scrape. Its
+// collection keeps its slot until it finishes, since the endpoint is still
+// busy with it; the jmx client of the collector should time out after the
+// budget to bound that. A collector whose jmx query failed, as told by its
+// up gauge, is reported as failed too, but its metrics are kept.
+type Runner struct {
+	logger  log.Logger
+	slots   chan struct{}
+	budget  time.Duration
+	entries []*runnerEntry
+
+	success  *prometheus.Desc
+	duration *prometheus.Desc
+}
+
+type runnerEntry struct {
+	runner    *Runner
+	name      string
+	collector prometheus.Collector
+}
+
+// NewRunner returns a runner limited to concurrency collections at once and
+// budget per collection. Either limit is disabled if 0.
+func NewRunner(logger log.Logger, concurrency int, budget time.Duration) *Runner {
+	var slots chan struct{}
+	if concurrency > 0 {
+		slots = make(chan struct{}, concurrency)
+	}
+
+	return &Runner{
+		logger: logger,
+		slots:  slots,
+		budget: budget,
+
+		success: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "exporter", "collector_success"),
+			"Whether the collector got a slot, finished within its budget and its jmx query succeeded.",
+			[]string{"collector"}, nil,
+		),
+		duration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "exporter", "collector_duration_seconds"),
+			"The time the collector took in seconds.",
+			[]string{"collector"}, nil,
+		),
+	}
+}
+
+// Wrap returns collector wrapped to collect within the concurrency limit
+// and budget of the runner, exported as the collector label of
+// hbase_exporter_collector_success. A Cache or Poller collects through it,
+// so the limits hold whichever way the exporter is scraped.
+func (r *Runner) Wrap(name string, collector prometheus.Collector) prometheus.Collector {
+	return &runnerEntry{
+		runner:    r,
+		name:      name,
+		collector: collector,
+	}
+}
+
+// Add runs collector on every collection of the runner, wrapped as by Wrap.
+// Add must not be called once the runner is registered.
+func (r *Runner) Add(name string, collector prometheus.Collector) {
+	r.entries = append(r.entries, &runnerEntry{
+		runner:    r,
+		name:      name,
+		collector: collector,
+	})
+}
+
+func (r *Runner) Describe(ch chan<- *prometheus.Desc) {
+	for _, entry := range r.entries {
+		entry.collector.Describe(ch)
+	}
+
+	ch <- r.success
+	ch <- r.duration
+}
+
+func (e *runnerEntry) Describe(ch chan<- *prometheus.Desc) {
+	e.collector.Describe(ch)
+
+	ch <- e.runner.success
+	ch <- e.runner.duration
+}
+
+func (e *runnerEntry) Collect(ch chan<- prometheus.Metric) {
+	e.runner.run(ch, e)
+}
+
+func (r *Runner) Collect(ch chan<- prometheus.Metric) {
+	var wg sync.WaitGroup
+	wg.Add(len(r.entries))
+	for _, entry := range r.entries {
+		go func(entry *runnerEntry) {
+			defer wg.Done()
+			r.run(ch, entry)
+		}(entry)
+	}
+	wg.Wait()
+}
+
+func (r *Runner) run(ch chan<- prometheus.Metric, entry *runnerEntry) {
+	begin := time.Now()
+	metrics, err := r.collect(entry)
+	duration := time.Since(begin)
+
+	if err != nil {
+		_ = level.Warn(r.logger).Log(
+			"msg", "collector dropped from the scrape",
+			"collector", entry.name,
+			"err", err,
+			"budget", r.budget,
+		)
+	}
+	for _, metric := range metrics {
+		ch <- metric
+	}
+
+	success := 0.0
+	if err == nil && !collectorFailed(entry.collector) {
+		success = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(r.success, prometheus.GaugeValue, success, entry.name)
+	ch <- prometheus.MustNewConstMetric(r.duration, prometheus.GaugeValue, duration.Seconds(), entry.name)
+}
+
+// upCollector is implemented by the collectors exporting an up gauge,
+// which they set to 0 when their jmx query fails.
+type upCollector interface {
+	upGauge() prometheus.Gauge
+}
+
+// collectorFailed reports whether the up gauge of collector, if it has one,
+// is 0 after its collection.
+func collectorFailed(collector prometheus.Collector) bool {
+	c, ok := collector.(upCollector)
+	if !ok {
+		return false
+	}
+
+	var m dto.Metric
+	if err := c.upGauge().Write(&m); err != nil {
+		return false
+	}
+	return m.GetGauge().GetValue() == 0
+}
+
+var (
+	errNoSlot         = errors.New("collector found no free slot within its budget")
+	errBudgetExceeded = errors.New("collector exceeded its budget")
+)
+
+// collect returns the metrics of entry, or an error if it did not get a
+// slot and finish within the budget, which counts from the call so that
+// waiting for a slot uses it up as well. The slot is released once the
+// collection finishes.
+func (r *Runner) collect(entry *runnerEntry) ([]prometheus.Metric, error) {
+	var deadline <-chan time.Time
+	if r.budget > 0 {
+		timer := time.NewTimer(r.budget)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	if r.slots != nil {
+		select {
+		case r.slots <- struct{}{}:
+		case <-deadline:
+			return nil, errNoSlot
+		}
+	}
+
+	done := make(chan []prometheus.Metric, 1)
+	go func() {
+		defer func() {
+			if r.slots != nil {
+				<-r.slots
+			}
+		}()
+
+		done <- collectMetrics(entry.collector)
+	}()
+
+	select {
+	case metrics := <-done:
+		return metrics, nil
+	case <-deadline:
+		return nil, errBudgetExceeded
+	}
+}
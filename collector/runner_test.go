@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sleepCollector exports a single gauge after sleeping for its duration.
+type sleepCollector struct {
+	desc     *prometheus.Desc
+	duration time.Duration
+}
+
+func newSleepCollector(name string, duration time.Duration) *sleepCollector {
+	return &sleepCollector{
+		desc:     prometheus.NewDesc(prometheus.BuildFQName(namespace, "test", name), "A test gauge.", nil, nil),
+		duration: duration,
+	}
+}
+
+func (c *sleepCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *sleepCollector) Collect(ch chan<- prometheus.Metric) {
+	time.Sleep(c.duration)
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+}
+
+// gatherSuccess returns hbase_exporter_collector_success by collector.
+func gatherSuccess(t *testing.T, c prometheus.Collector) map[string]float64 {
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	success := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "hbase_exporter_collector_success" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			success[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+	return success
+}
+
+func TestRunnerBudgetIncludesSlotWait(t *testing.T) {
+	// With one slot, the first collector finishes after 200ms, the second
+	// gets the slot then but runs out of budget at 300ms and the third
+	// never gets a slot within its budget.
+	r := NewRunner(log.NewNopLogger(), 1, 300*time.Millisecond)
+	for _, name := range []string{"a", "b", "c"} {
+		r.Add(name, newSleepCollector(name, 200*time.Millisecond))
+	}
+
+	success := gatherSuccess(t, r)
+	if len(success) != 3 {
+		t.Fatalf("got success of %d collectors, want 3", len(success))
+	}
+
+	succeeded := 0
+	for _, v := range success {
+		succeeded += int(v)
+	}
+	if succeeded != 1 {
+		t.Errorf("got %d successful collectors, want 1: %v", succeeded, success)
+	}
+}
+
+func TestRunnerUnlimited(t *testing.T) {
+	r := NewRunner(log.NewNopLogger(), 0, 0)
+	for _, name := range []string{"a", "b", "c"} {
+		r.Add(name, newSleepCollector(name, 10*time.Millisecond))
+	}
+
+	for name, v := range gatherSuccess(t, r) {
+		if v != 1 {
+			t.Errorf("collector %s failed", name)
+		}
+	}
+}
+
+func TestRunnerWrapInCache(t *testing.T) {
+	// The limits of the runner hold for the collections of the cache.
+	r := NewRunner(log.NewNopLogger(), 1, 300*time.Millisecond)
+	c := NewCache(time.Minute)
+	for _, name := range []string{"a", "b"} {
+		c.Add(name, r.Wrap(name, newSleepCollector(name, 200*time.Millisecond)))
+	}
+
+	success := gatherSuccess(t, c)
+	if success["a"]+success["b"] != 1 {
+		t.Errorf("got %v, want one successful collector", success)
+	}
+}
+
+func TestRunnerReportsCollectorFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL + "/jmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jmx := NewJmxClient(u, 0, 0)
+
+	logger := log.NewNopLogger()
+	r := NewRunner(logger, 0, 0)
+	r.Add("wal", NewRsWal(logger, jmx))
+	r.Add("region", NewRsRegion(logger, jmx, RsRegionOptions{}))
+	r.Add("test", newSleepCollector("test", 0))
+
+	success := gatherSuccess(t, r)
+	if success["wal"] != 0 || success["region"] != 0 {
+		t.Errorf("got %v, want the collectors failing on jmx reported as failed", success)
+	}
+	if success["test"] != 1 {
+		t.Errorf("got %v, want the collector without up gauge reported as successful", success)
+	}
+}
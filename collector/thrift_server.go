@@ -0,0 +1,232 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseThriftServerLabels      = []string{"host", "role", "server"}
+	defaultHBaseThriftServerLabelValues = func(thriftServer thriftServerResponse) []string {
+		return []string{
+			thriftServer.Host,
+			strings.ToLower(thriftServer.Role),
+			strings.ToLower(beanProperty(thriftServer.Name, "sub")),
+		}
+	}
+)
+
+type thriftServerMetric struct {
+	Type   prometheus.ValueType
+	Desc   *prometheus.Desc
+	Value  func(thriftServer thriftServerResponse) float64
+	Labels func(thriftServer thriftServerResponse) []string
+}
+
+// ThriftServer collects the metrics of an HBase thrift gateway. The server
+// label tells the ThriftOne and ThriftTwo beans apart.
+type ThriftServer struct {
+	logger log.Logger
+	jmx    *JmxClient
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	metrics    []*thriftServerMetric
+	histograms []*hbaseHistogram
+	exceptions *prometheus.Desc
+}
+
+func NewThriftServer(logger log.Logger, jmx *JmxClient) *ThriftServer {
+	subsystem := "thrift"
+
+	return &ThriftServer{
+		logger: logger,
+		jmx:    jmx,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase thrift endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase thrift scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		metrics: []*thriftServerMetric{
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "call_queue_len"),
+					"The number of calls waiting in the thrift call queue.",
+					defaultHBaseThriftServerLabels, nil,
+				),
+				Value: func(thriftServer thriftServerResponse) float64 {
+					return thriftServer.CallQueueLen
+				},
+				Labels: defaultHBaseThriftServerLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "num_active_workers"),
+					"The number of thrift worker threads busy with a call.",
+					defaultHBaseThriftServerLabels, nil,
+				),
+				Value: func(thriftServer thriftServerResponse) float64 {
+					return thriftServer.NumActiveWorkers
+				},
+				Labels: defaultHBaseThriftServerLabelValues,
+			},
+		},
+
+		histograms: []*hbaseHistogram{
+			{
+				Attr: "batchGet",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "batch_get_time_ms"),
+					"The time of batch gets in milliseconds.",
+					defaultHBaseThriftServerLabels, nil,
+				),
+			},
+			{
+				Attr: "batchMutate",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "batch_mutate_time_ms"),
+					"The time of batch mutations in milliseconds.",
+					defaultHBaseThriftServerLabels, nil,
+				),
+			},
+			{
+				Attr: "timeInQueue",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "time_in_queue_ms"),
+					"The time calls spent in the thrift call queue in milliseconds.",
+					defaultHBaseThriftServerLabels, nil,
+				),
+			},
+			{
+				Attr: "thriftCall",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "call_time_ms"),
+					"The time of thrift calls in milliseconds.",
+					defaultHBaseThriftServerLabels, nil,
+				),
+			},
+			{
+				Attr: "slowThriftCall",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "slow_call_time_ms"),
+					"The time of slow thrift calls in milliseconds.",
+					defaultHBaseThriftServerLabels, nil,
+				),
+			},
+		},
+
+		exceptions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "exceptions"),
+			"The number of failed thrift calls, by exception.",
+			append(defaultHBaseThriftServerLabels, "exception"), nil,
+		),
+	}
+}
+
+func (m *ThriftServer) upGauge() prometheus.Gauge {
+	return m.up
+}
+
+func (m *ThriftServer) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range m.metrics {
+		ch <- metric.Desc
+	}
+	for _, histogram := range m.histograms {
+		ch <- histogram.Desc
+	}
+	ch <- m.exceptions
+
+	ch <- m.up.Desc()
+	ch <- m.totalScrapes.Desc()
+	ch <- m.jsonParseFailures.Desc()
+}
+
+func (m *ThriftServer) Collect(ch chan<- prometheus.Metric) {
+	m.totalScrapes.Inc()
+	defer func() {
+		ch <- m.up
+		ch <- m.totalScrapes
+		ch <- m.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name=Thrift,sub=*")
+	if err != nil {
+		if isJmxReadError(err) {
+			m.jsonParseFailures.Inc()
+		}
+		m.up.Set(0)
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to fetch thrift metrics",
+			"err", err,
+		)
+		return
+	}
+
+	beans, err := allBeans(bts)
+	if err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode thrift metrics",
+			"err", err,
+		)
+		return
+	}
+	m.up.Set(1)
+
+	for _, bean := range beans {
+		var thriftServerResp thriftServerResponse
+		if err := json.Unmarshal([]byte(bean.Raw), &thriftServerResp); err != nil {
+			m.jsonParseFailures.Inc()
+			_ = level.Warn(m.logger).Log(
+				"msg", "failed to decode thrift metrics",
+				"bean", bean.Get("name").String(),
+				"err", err,
+			)
+			continue
+		}
+
+		for _, metric := range m.metrics {
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				metric.Value(thriftServerResp),
+				metric.Labels(thriftServerResp)...,
+			)
+		}
+
+		labels := defaultHBaseThriftServerLabelValues(thriftServerResp)
+		attrs := bean.Map()
+
+		collectHistograms(ch, m.histograms, attrs, labels...)
+
+		for k, v := range attrs {
+			if !strings.HasPrefix(k, "exceptions.") {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				m.exceptions,
+				prometheus.CounterValue,
+				v.Float(),
+				append(labels, strings.TrimPrefix(k, "exceptions."))...,
+			)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package collector
+
+type thriftServerResponse struct {
+	Name             string  `json:"name"`
+	Host             string  `json:"tag.Hostname"`
+	Role             string  `json:"tag.Context"`
+	CallQueueLen     float64 `json:"callQueueLen"`
+	NumActiveWorkers float64 `json:"numActiveWorkers"`
+}
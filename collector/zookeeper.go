@@ -0,0 +1,194 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultHBaseZooKeeperLabels = []string{"host", "role"}
+
+	// hbaseZooKeeperExceptions maps the exception counters of the zookeeper
+	// bean to the exception label.
+	hbaseZooKeeperExceptions = map[string]string{
+		"AUTHFAILED Exception":           "auth_failed",
+		"CONNECTIONLOSS Exception":       "connection_loss",
+		"DATAINCONSISTENCY Exception":    "data_inconsistency",
+		"INVALIDACL Exception":           "invalid_acl",
+		"NOAUTH Exception":               "no_auth",
+		"OPERATIONTIMEOUT Exception":     "operation_timeout",
+		"RUNTIMEINCONSISTENCY Exception": "runtime_inconsistency",
+		"SESSIONEXPIRED Exception":       "session_expired",
+		"SYSTEMERROR Exception":          "system_error",
+	}
+)
+
+// HBaseZooKeeper collects the zookeeper client metrics of a master or
+// regionserver.
+type HBaseZooKeeper struct {
+	logger  log.Logger
+	jmx     *JmxClient
+	service string
+
+	up                              prometheus.Gauge
+	totalScrapes, jsonParseFailures prometheus.Counter
+
+	failedCalls *prometheus.Desc
+	exceptions  *prometheus.Desc
+	histograms  []*hbaseHistogram
+}
+
+// NewHBaseZooKeeper returns a collector for the zookeeper bean of service,
+// which is either MasterService or RegionServerService. The bean carries no
+// role of its own, so the role label is taken from service.
+func NewHBaseZooKeeper(logger log.Logger, jmx *JmxClient, service string) *HBaseZooKeeper {
+	subsystem := "zookeeper"
+
+	return &HBaseZooKeeper{
+		logger:  logger,
+		jmx:     jmx,
+		service: service,
+
+		up: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "up"),
+			Help: "Was the last scrape of the HBase zookeeper endpoint successful.",
+		}),
+		totalScrapes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "total_scrapes"),
+			Help: "Current total HBase zookeeper scrapes.",
+		}),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, subsystem, "json_parse_failures"),
+			Help: "Number of errors while parsing JSON.",
+		}),
+
+		failedCalls: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "failed_calls"),
+			"The number of failed zookeeper calls.",
+			defaultHBaseZooKeeperLabels, nil,
+		),
+		exceptions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "exceptions"),
+			"The number of zookeeper client exceptions, by exception.",
+			append(defaultHBaseZooKeeperLabels, "exception"), nil,
+		),
+
+		histograms: []*hbaseHistogram{
+			{
+				Attr: "ReadOperationLatency",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "read_operation_latency_ms"),
+					"The latency of zookeeper read operations in milliseconds.",
+					defaultHBaseZooKeeperLabels, nil,
+				),
+			},
+			{
+				Attr: "WriteOperationLatency",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "write_operation_latency_ms"),
+					"The latency of zookeeper write operations in milliseconds.",
+					defaultHBaseZooKeeperLabels, nil,
+				),
+			},
+			{
+				Attr: "SyncOperationLatency",
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, subsystem, "sync_operation_latency_ms"),
+					"The latency of zookeeper sync operations in milliseconds.",
+					defaultHBaseZooKeeperLabels, nil,
+				),
+			},
+		},
+	}
+}
+
+func (m *HBaseZooKeeper) upGauge() prometheus.Gauge {
+	return m.up
+}
+
+func (m *HBaseZooKeeper) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.failedCalls
+	ch <- m.exceptions
+	for _, histogram := range m.histograms {
+		ch <- histogram.Desc
+	}
+
+	ch <- m.up.Desc()
+	ch <- m.totalScrapes.Desc()
+	ch <- m.jsonParseFailures.Desc()
+}
+
+func (m *HBaseZooKeeper) Collect(ch chan<- prometheus.Metric) {
+	m.totalScrapes.Inc()
+	defer func() {
+		ch <- m.up
+		ch <- m.totalScrapes
+		ch <- m.jsonParseFailures
+	}()
+
+	bts, err := fetchJmx(m.logger, m.jmx, "Hadoop:service=HBase,name=ZOOKEEPER,sub=ZOOKEEPER")
+	if err != nil {
+		if isJmxReadError(err) {
+			m.jsonParseFailures.Inc()
+		}
+		m.up.Set(0)
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to fetch zookeeper metrics",
+			"err", err,
+		)
+		return
+	}
+
+	bean, err := firstBean(bts)
+	if err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode zookeeper metrics",
+			"err", err,
+		)
+		return
+	}
+
+	var hbaseZooKeeperResp hbaseZooKeeperResponse
+	if err := json.Unmarshal([]byte(bean.Raw), &hbaseZooKeeperResp); err != nil {
+		m.up.Set(0)
+		m.jsonParseFailures.Inc()
+		_ = level.Warn(m.logger).Log(
+			"msg", "failed to decode zookeeper metrics",
+			"err", err,
+		)
+		return
+	}
+	m.up.Set(1)
+
+	labels := []string{hbaseZooKeeperResp.Host, strings.ToLower(m.service)}
+	attrs := bean.Map()
+
+	ch <- prometheus.MustNewConstMetric(
+		m.failedCalls,
+		prometheus.CounterValue,
+		hbaseZooKeeperResp.TotalFailedZKCalls,
+		labels...,
+	)
+
+	for attr, exception := range hbaseZooKeeperExceptions {
+		v, ok := attrs[attr]
+		if !ok {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			m.exceptions,
+			prometheus.CounterValue,
+			v.Float(),
+			append(labels, exception)...,
+		)
+	}
+
+	collectHistograms(ch, m.histograms, attrs, labels...)
+}
@@ -0,0 +1,6 @@
+package collector
+
+type hbaseZooKeeperResponse struct {
+	Host               string  `json:"tag.Hostname"`
+	TotalFailedZKCalls float64 `json:"TotalFailedZKCalls"`
+}
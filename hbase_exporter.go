@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
 	"strings"
 
 	"./collector"
@@ -77,9 +78,63 @@ func main() {
 		hbaseRegionserverURI = kingpin.Flag("hbase.regionserver.uri",
 			"HTTP jmx address of an HBase regionserver node.").
 			Default("http://localhost:60030/jmx").Envar("HBASE_REGIONSERVER_URL").String()
+		hbaseThriftURI = kingpin.Flag("hbase.thrift.uri",
+			"HTTP jmx address of an HBase thrift server.").
+			Default("http://localhost:9095/jmx").Envar("HBASE_THRIFT_URL").String()
+		hbaseRestURI = kingpin.Flag("hbase.rest.uri",
+			"HTTP jmx address of an HBase rest server.").
+			Default("http://localhost:8085/jmx").Envar("HBASE_REST_URL").String()
+		hbaseRole = kingpin.Flag("hbase.role",
+			"Role of the HBase process to export. Valid roles are master, regionserver, thrift and rest").
+			Default("regionserver").Envar("HBASE_ROLE").Enum("master", "regionserver", "thrift", "rest")
 		hbaseIsMaster = kingpin.Flag("hbase.master",
-			"Is hbase master.").
+			"Is hbase master. Same as --hbase.role=master.").
 			Default("false").Envar("HBASE_IS_MASTER").Bool()
+		hbaseRegionUnknownAttributes = kingpin.Flag("hbase.region.unknown-attributes",
+			"Export region attributes without a dedicated metric as hbase_region_attribute.").
+			Default("false").Envar("HBASE_REGION_UNKNOWN_ATTRIBUTES").Bool()
+		hbaseRegionHotspots = kingpin.Flag("hbase.region.hotspots",
+			"Export per-region request rates, hotspot scores and the hottest regions.").
+			Default("false").Envar("HBASE_REGION_HOTSPOTS").Bool()
+		hbaseRegionHotspotTopK = kingpin.Flag("hbase.region.hotspots.top-k",
+			"Number of regions exported as hbase_region_hottest_request_rate.").
+			Default("10").Envar("HBASE_REGION_HOTSPOTS_TOP_K").Int()
+		hbaseRegionLifecycle = kingpin.Flag("hbase.region.lifecycle",
+			"Export the regions opened and closed on the regionserver and the age of each region.").
+			Default("false").Envar("HBASE_REGION_LIFECYCLE").Bool()
+		hbaseRegionLifecycleLog = kingpin.Flag("hbase.region.lifecycle.log",
+			"Log every region opened, closed or split on the regionserver.").
+			Default("false").Envar("HBASE_REGION_LIFECYCLE_LOG").Bool()
+		hbaseRegionPhoenixLabels = kingpin.Flag("hbase.region.phoenix-labels",
+			"Add phoenix_schema and phoenix_table labels, split from Phoenix table names such as SCHEMA.TABLE, to the region metrics.").
+			Default("false").Envar("HBASE_REGION_PHOENIX_LABELS").Bool()
+		hbaseUsers = kingpin.Flag("hbase.users",
+			"Export the per user request metrics of the regionserver.").
+			Default("false").Envar("HBASE_USERS").Bool()
+		hbaseUsersAllowlist = kingpin.Flag("hbase.users.allowlist",
+			"Regexp of the users exported by the per user metrics. All users are exported if empty.").
+			Default("").Envar("HBASE_USERS_ALLOWLIST").String()
+		hbaseUsersDenylist = kingpin.Flag("hbase.users.denylist",
+			"Regexp of the users not exported by the per user metrics.").
+			Default("").Envar("HBASE_USERS_DENYLIST").String()
+		hbaseJmxMaxResponseSize = kingpin.Flag("hbase.jmx.max-response-size",
+			"Maximum size of a single jmx response, e.g. 256MB. Larger responses fail the scrape of the collector and count as its json_parse_failures. Unlimited if 0B.").
+			Default("256MB").Envar("HBASE_JMX_MAX_RESPONSE_SIZE").Bytes()
+		cacheTTL = kingpin.Flag("cache.ttl",
+			"Serve the metrics of each collector from a snapshot refreshed in the background once older than this, so concurrent scrapes share one JMX request. Disabled if 0.").
+			Default("0s").Envar("CACHE_TTL").Duration()
+		pollInterval = kingpin.Flag("poll.interval",
+			"Poll JMX in the background at this interval and serve scrapes from the last poll, instead of polling on every scrape. Disabled if 0, takes precedence over cache.ttl.").
+			Default("0s").Envar("POLL_INTERVAL").Duration()
+		pollMaxAge = kingpin.Flag("poll.max-age",
+			"Stop exporting the metrics of a collector whose last poll is older than this. Defaults to three poll intervals if 0.").
+			Default("0s").Envar("POLL_MAX_AGE").Duration()
+		collectorConcurrency = kingpin.Flag("collector.concurrency",
+			"Maximum number of collectors querying JMX at once, also when caching or polling. Unlimited if 0.").
+			Default("4").Envar("COLLECTOR_CONCURRENCY").Int()
+		collectorBudget = kingpin.Flag("collector.budget",
+			"Time, including the wait for a concurrency slot, after which the metrics of a collector are dropped from the scrape and it is reported as failed. Also the timeout of each JMX request. Disabled if 0.").
+			Default("0s").Envar("COLLECTOR_BUDGET").Duration()
 		logLevel = kingpin.Flag("log.level",
 			"Sets the loglevel. Valid levels are debug, info, warn, error").
 			Default("info").Envar("LOG_LEVEL").String()
@@ -115,18 +170,137 @@ func main() {
 		os.Exit(1)
 	}
 
+	hbaseThriftURL, err := url.Parse(*hbaseThriftURI)
+	if err != nil {
+		_ = level.Error(logger).Log(
+			"msg", "failed to parse hbase.thrift.uri",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	hbaseRestURL, err := url.Parse(*hbaseRestURI)
+	if err != nil {
+		_ = level.Error(logger).Log(
+			"msg", "failed to parse hbase.rest.uri",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	var hbaseUsersOptions collector.RsUserOptions
+	if *hbaseUsersAllowlist != "" {
+		hbaseUsersOptions.Allowlist, err = regexp.Compile("^(?:" + *hbaseUsersAllowlist + ")$")
+		if err != nil {
+			_ = level.Error(logger).Log(
+				"msg", "failed to parse hbase.users.allowlist",
+				"err", err,
+			)
+			os.Exit(1)
+		}
+	}
+	if *hbaseUsersDenylist != "" {
+		hbaseUsersOptions.Denylist, err = regexp.Compile("^(?:" + *hbaseUsersDenylist + ")$")
+		if err != nil {
+			_ = level.Error(logger).Log(
+				"msg", "failed to parse hbase.users.denylist",
+				"err", err,
+			)
+			os.Exit(1)
+		}
+	}
+
+	hbaseMasterJmx := collector.NewJmxClient(hbaseMasterURL, int64(*hbaseJmxMaxResponseSize), *collectorBudget)
+	hbaseRegionserverJmx := collector.NewJmxClient(hbaseRegionserverURL, int64(*hbaseJmxMaxResponseSize), *collectorBudget)
+	hbaseThriftJmx := collector.NewJmxClient(hbaseThriftURL, int64(*hbaseJmxMaxResponseSize), *collectorBudget)
+	hbaseRestJmx := collector.NewJmxClient(hbaseRestURL, int64(*hbaseJmxMaxResponseSize), *collectorBudget)
+
+	if *hbaseIsMaster {
+		*hbaseRole = "master"
+	}
+
 	versionMetric := version.NewCollector(Name)
 	prometheus.MustRegister(versionMetric)
 
-	if *hbaseIsMaster {
-		prometheus.MustRegister(collector.NewHBaseJvm(logger, hbaseMasterURL))
-		prometheus.MustRegister(collector.NewMasterServer(logger, hbaseMasterURL))
-	} else {
-		prometheus.MustRegister(collector.NewHBaseJvm(logger, hbaseRegionserverURL))
-		prometheus.MustRegister(collector.NewRsServer(logger, hbaseRegionserverURL))
+	if *pollMaxAge == 0 {
+		*pollMaxAge = 3 * *pollInterval
+	}
+
+	poller := collector.NewPoller(*pollInterval, *pollMaxAge)
+	cache := collector.NewCache(*cacheTTL)
+	runner := collector.NewRunner(logger, *collectorConcurrency, *collectorBudget)
+	register := func(name string, c prometheus.Collector) {
+		if *pollInterval > 0 {
+			poller.Add(name, runner.Wrap(name, c))
+			return
+		}
+		if *cacheTTL > 0 {
+			cache.Add(name, runner.Wrap(name, c))
+			return
+		}
+		runner.Add(name, c)
+	}
+
+	switch *hbaseRole {
+	case "master":
+		register("jvm", collector.NewHBaseJvm(logger, hbaseMasterJmx))
+		register("server", collector.NewMasterServer(logger, hbaseMasterJmx))
+		register("procedure", collector.NewMasterProcedure(logger, hbaseMasterJmx))
+		register("filesystem", collector.NewMasterFileSystem(logger, hbaseMasterJmx))
+		register("snapshot", collector.NewMasterSnapshot(logger, hbaseMasterJmx))
+		register("ipc", collector.NewHBaseIpc(logger, hbaseMasterJmx, collector.MasterService))
+		register("zookeeper", collector.NewHBaseZooKeeper(logger, hbaseMasterJmx, collector.MasterService))
+		register("quota", collector.NewHBaseQuota(logger, hbaseMasterJmx, collector.MasterService))
+		register("coprocessor", collector.NewHBaseCoprocessor(logger, hbaseMasterJmx, collector.MasterService))
+	case "thrift":
+		register("jvm", collector.NewHBaseJvm(logger, hbaseThriftJmx))
+		register("thrift", collector.NewThriftServer(logger, hbaseThriftJmx))
+	case "rest":
+		register("jvm", collector.NewHBaseJvm(logger, hbaseRestJmx))
+		register("rest", collector.NewRestServer(logger, hbaseRestJmx))
+	default:
+		register("jvm", collector.NewHBaseJvm(logger, hbaseRegionserverJmx))
+		// The cache, compaction, hdfs and mob metrics share the fetch of the
+		// Server bean.
+		register("server", collector.NewRsServer(logger, hbaseRegionserverJmx,
+			collector.NewRsCache(logger, hbaseRegionserverJmx),
+			collector.NewRsCompaction(logger),
+			collector.NewRsHdfs(logger, hbaseRegionserverJmx),
+			collector.NewRsMob(logger),
+		))
+		register("ipc", collector.NewHBaseIpc(logger, hbaseRegionserverJmx, collector.RegionServerService))
+		register("zookeeper", collector.NewHBaseZooKeeper(logger, hbaseRegionserverJmx, collector.RegionServerService))
+		register("quota", collector.NewHBaseQuota(logger, hbaseRegionserverJmx, collector.RegionServerService))
+		register("coprocessor", collector.NewHBaseCoprocessor(logger, hbaseRegionserverJmx, collector.RegionServerService))
+		register("phoenix", collector.NewRsPhoenix(logger, hbaseRegionserverJmx))
+		register("wal", collector.NewRsWal(logger, hbaseRegionserverJmx))
+		register("replication", collector.NewRsReplication(logger, hbaseRegionserverJmx))
+		register("memory", collector.NewRsMemory(logger, hbaseRegionserverJmx))
+
+		register("region", collector.NewRsRegion(logger, hbaseRegionserverJmx, collector.RsRegionOptions{
+			UnknownAttributes: *hbaseRegionUnknownAttributes,
+			Hotspots:          *hbaseRegionHotspots,
+			HotspotTopK:       *hbaseRegionHotspotTopK,
+			Lifecycle:         *hbaseRegionLifecycle,
+			LogRegionChanges:  *hbaseRegionLifecycleLog,
+			PhoenixLabels:     *hbaseRegionPhoenixLabels,
+		}))
 
-		prometheus.MustRegister(collector.NewRsRegion(logger, hbaseRegionserverURL))
+		if *hbaseUsers {
+			register("user", collector.NewRsUser(logger, hbaseRegionserverJmx, hbaseUsersOptions))
+		}
 	}
+
+	switch {
+	case *pollInterval > 0:
+		prometheus.MustRegister(poller)
+		poller.Start()
+	case *cacheTTL > 0:
+		prometheus.MustRegister(cache)
+	default:
+		prometheus.MustRegister(runner)
+	}
+
 	level.Info(logger).Log("msg", "Build context", "build_context", version.BuildContext())
 	level.Info(logger).Log("msg", "Starting hbase_exporter", "version", version.Info())
 